@@ -2,10 +2,12 @@ package benchmarks
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"playground/bmssp"
 	"playground/common"
 	"playground/dijkstra"
+	"playground/graphjson"
 	"testing"
 	"time"
 )
@@ -19,15 +21,20 @@ func BenchmarkComparison_LinearGraph_Small(b *testing.B) {
 	l := 3
 
 	b.Run("BMSSP", func(b *testing.B) {
+		algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
 			_, _ = algo.Solve()
 		}
+		reportStats(b, bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources).WithStats())
 	})
 
 	b.Run("Dijkstra", func(b *testing.B) {
+		algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
 			_, _ = algo.Solve()
 		}
 	})
@@ -40,15 +47,20 @@ func BenchmarkComparison_LinearGraph_Medium(b *testing.B) {
 	l := 4
 
 	b.Run("BMSSP", func(b *testing.B) {
+		algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
 			_, _ = algo.Solve()
 		}
+		reportStats(b, bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources).WithStats())
 	})
 
 	b.Run("Dijkstra", func(b *testing.B) {
+		algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
 			_, _ = algo.Solve()
 		}
 	})
@@ -61,15 +73,42 @@ func BenchmarkComparison_LinearGraph_Large(b *testing.B) {
 	l := 5
 
 	b.Run("BMSSP", func(b *testing.B) {
+		algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
 			_, _ = algo.Solve()
 		}
+		reportStats(b, bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources).WithStats())
 	})
 
 	b.Run("Dijkstra", func(b *testing.B) {
+		algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
+		_, _ = algo.Solve()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, _ = algo.Solve()
+		}
+	})
+
+	csr, err := common.NewCSRGraph(g)
+	if err != nil {
+		b.Fatalf("NewCSRGraph() returned an error: %v", err)
+	}
+	b.Run("BMSSP_CSR", func(b *testing.B) {
+		algo := bmssp.NewBMSSPAlgorithmCSR(csr, l, float64(B), sources)
+		_, _ = algo.Solve()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, _ = algo.Solve()
+		}
+	})
+
+	b.Run("Dijkstra_CSR", func(b *testing.B) {
+		algo := dijkstra.NewDijkstraAlgorithmCSR(csr, sources, nil)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
 			_, _ = algo.Solve()
 		}
 	})
@@ -82,15 +121,20 @@ func BenchmarkComparison_GridGraph_Small(b *testing.B) {
 	l := 3
 
 	b.Run("BMSSP", func(b *testing.B) {
+		algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
 			_, _ = algo.Solve()
 		}
+		reportStats(b, bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources).WithStats())
 	})
 
 	b.Run("Dijkstra", func(b *testing.B) {
+		algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
 			_, _ = algo.Solve()
 		}
 	})
@@ -103,15 +147,30 @@ func BenchmarkComparison_GridGraph_Medium(b *testing.B) {
 	l := 4
 
 	b.Run("BMSSP", func(b *testing.B) {
+		algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
+		_, _ = algo.Solve()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, _ = algo.Solve()
+		}
+		reportStats(b, bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources).WithStats())
+	})
+
+	b.Run("BMSSP_BlockD", func(b *testing.B) {
+		algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources).WithDataStructure(bmssp.BlockD)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
 			_, _ = algo.Solve()
 		}
+		reportStats(b, bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources).WithDataStructure(bmssp.BlockD).WithStats())
 	})
 
 	b.Run("Dijkstra", func(b *testing.B) {
+		algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
 			_, _ = algo.Solve()
 		}
 	})
@@ -126,15 +185,20 @@ func BenchmarkComparison_RandomGraph_Sparse(b *testing.B) {
 	l := 4
 
 	b.Run("BMSSP", func(b *testing.B) {
+		algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
 			_, _ = algo.Solve()
 		}
+		reportStats(b, bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources).WithStats())
 	})
 
 	b.Run("Dijkstra", func(b *testing.B) {
+		algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
 			_, _ = algo.Solve()
 		}
 	})
@@ -149,15 +213,30 @@ func BenchmarkComparison_RandomGraph_Dense(b *testing.B) {
 	l := 4
 
 	b.Run("BMSSP", func(b *testing.B) {
+		algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
 			_, _ = algo.Solve()
 		}
+		reportStats(b, bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources).WithStats())
+	})
+
+	b.Run("BMSSP_DegreeReduced", func(b *testing.B) {
+		algo := bmssp.NewDegreeReducedBMSSP(g, l, float64(B), sources)
+		_, _ = algo.Solve()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, _ = algo.Solve()
+		}
+		reportStats(b, bmssp.NewDegreeReducedBMSSP(g, l, float64(B), sources).WithStats())
 	})
 
 	b.Run("Dijkstra", func(b *testing.B) {
+		algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
 			_, _ = algo.Solve()
 		}
 	})
@@ -170,15 +249,20 @@ func BenchmarkComparison_MultiSource(b *testing.B) {
 	l := 4
 
 	b.Run("BMSSP", func(b *testing.B) {
+		algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
 			_, _ = algo.Solve()
 		}
+		reportStats(b, bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources).WithStats())
 	})
 
 	b.Run("Dijkstra_MultiSource", func(b *testing.B) {
+		algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
 			_, _ = algo.Solve()
 		}
 	})
@@ -191,15 +275,20 @@ func BenchmarkComparison_Bounded(b *testing.B) {
 	l := 3
 
 	b.Run("BMSSP_Bounded", func(b *testing.B) {
+		algo := bmssp.NewBMSSPAlgorithm(g, l, boundary, sources)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := bmssp.NewBMSSPAlgorithm(g, l, boundary, sources)
 			_, _ = algo.Solve()
 		}
+		reportStats(b, bmssp.NewBMSSPAlgorithm(g, l, boundary, sources).WithStats())
 	})
 
 	b.Run("Dijkstra_Bounded", func(b *testing.B) {
+		algo := dijkstra.NewDijkstraAlgorithm(g, sources, &boundary)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := dijkstra.NewDijkstraAlgorithm(g, sources, &boundary)
 			_, _ = algo.Solve()
 		}
 	})
@@ -213,81 +302,194 @@ func BenchmarkMemory_Comparison(b *testing.B) {
 
 	b.Run("BMSSP_Memory", func(b *testing.B) {
 		b.ReportAllocs()
+		algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources)
 			_, _ = algo.Solve()
 		}
+		reportStats(b, bmssp.NewBMSSPAlgorithm(g, l, float64(B), sources).WithStats())
 	})
 
 	b.Run("Dijkstra_Memory", func(b *testing.B) {
 		b.ReportAllocs()
+		algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
+		_, _ = algo.Solve()
+		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			algo := dijkstra.NewDijkstraAlgorithm(g, sources, nil)
 			_, _ = algo.Solve()
 		}
 	})
 }
 
-// --- Graph Generation Utilities ---
+func BenchmarkMemory_Workspace(b *testing.B) {
+	g := createGridGraph(50, 50) // 2500 vertices
+	sources := []int{0}
+	B := 10000.0
+
+	b.Run("BMSSP_Workspace", func(b *testing.B) {
+		w, err := bmssp.NewWorkspace(g)
+		if err != nil {
+			b.Fatalf("NewWorkspace() returned an error: %v", err)
+		}
+		_ = w.Query(sources, B)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = w.Query(sources, B)
+		}
+	})
 
-func createLinearGraph(n int) *common.Graph {
-	g := &common.Graph{
-		N:   n,
-		Adj: make(map[int][]common.Edge),
+	b.Run("Dijkstra_Workspace", func(b *testing.B) {
+		w, err := dijkstra.NewWorkspace(g)
+		if err != nil {
+			b.Fatalf("NewWorkspace() returned an error: %v", err)
+		}
+		_ = w.Query(sources, B)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = w.Query(sources, B)
+		}
+	})
+}
+
+// BenchmarkReordering solves on a grid whose vertex ids were shuffled, as they arrive
+// from most data sources, and on the same grid after each reordering pass.
+func BenchmarkReordering(b *testing.B) {
+	const rows, cols = 150, 150
+	g, coords := createShuffledGridGraph(rows, cols, 42)
+	source := 0
+
+	orderings := []struct {
+		name    string
+		reorder func(*common.Graph[float64]) (*common.ReorderedGraph[float64], error)
+	}{
+		{"Shuffled", func(g *common.Graph[float64]) (*common.ReorderedGraph[float64], error) {
+			order := make([]int, g.N)
+			for v := range order {
+				order[v] = v
+			}
+			return common.Reorder(g, order)
+		}},
+		{"BFS", common.ReorderBFS[float64]},
+		{"RCM", common.ReorderRCM[float64]},
+		{"Degree", common.ReorderByDegree[float64]},
+		{"Hilbert", func(g *common.Graph[float64]) (*common.ReorderedGraph[float64], error) {
+			return common.ReorderHilbert(g, coords)
+		}},
 	}
-	edges := make([]common.Edge, 0, n-1)
-	for i := 0; i < n-1; i++ {
-		edges = append(edges, common.Edge{U: i, V: i + 1, Weight: 1.0})
+	for _, o := range orderings {
+		rg, err := o.reorder(g)
+		if err != nil {
+			b.Fatalf("%s: reordering returned an error: %v", o.name, err)
+		}
+		csr, err := common.NewCSRGraph(rg.Graph)
+		if err != nil {
+			b.Fatalf("NewCSRGraph() returned an error: %v", err)
+		}
+		sources, _ := rg.Perm.NewIDs([]int{source})
+
+		b.Run(o.name+"/BMSSP", func(b *testing.B) {
+			w := bmssp.NewWorkspaceCSR(csr)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = w.Query(sources, math.Inf(1))
+			}
+		})
+		b.Run(o.name+"/Dijkstra", func(b *testing.B) {
+			w := dijkstra.NewWorkspaceCSR(csr)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = w.Query(sources, math.Inf(1))
+			}
+		})
 	}
-	for _, e := range edges {
-		g.Adj[e.U] = append(g.Adj[e.U], e)
-		g.Adj[e.V] = append(g.Adj[e.V], common.Edge{U: e.V, V: e.U, Weight: e.Weight})
+}
+
+// BenchmarkJSONCodecs encodes and decodes the document of a large grid graph with every
+// graphjson codec.
+func BenchmarkJSONCodecs(b *testing.B) {
+	g := createGridGraph(400, 400)
+	doc := graphjson.FromGraph(g)
+	data, err := graphjson.Std.Marshal(doc)
+	if err != nil {
+		b.Fatalf("Marshal() returned an error: %v", err)
+	}
+	for _, c := range graphjson.Codecs() {
+		b.Run(c.Name()+"/Encode", func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.Marshal(doc); err != nil {
+					b.Fatalf("Marshal() returned an error: %v", err)
+				}
+			}
+		})
+		b.Run(c.Name()+"/Decode", func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var got graphjson.Graph[float64]
+				if err := c.Unmarshal(data, &got); err != nil {
+					b.Fatalf("Unmarshal() returned an error: %v", err)
+				}
+			}
+		})
 	}
-	g.Edges = edges
-	return g
 }
 
-func createGridGraph(rows, cols int) *common.Graph {
-	n := rows * cols
-	g := &common.Graph{
-		N:   n,
-		Adj: make(map[int][]common.Edge),
+// reportStats stops the timer, runs one instrumented solve, logs its counters and
+// reports the relaxation count alongside the timings. It must follow the timed loop.
+func reportStats(b *testing.B, algo interface {
+	Solve() (*common.Result[float64], error)
+	Stats() *bmssp.Stats
+}) {
+	b.Helper()
+	b.StopTimer()
+	if _, err := algo.Solve(); err != nil {
+		b.Fatalf("Solve() returned an error: %v", err)
 	}
-	edges := make([]common.Edge, 0)
+	stats := algo.Stats()
+	b.ReportMetric(float64(stats.Relaxations), "relaxations")
+	b.Logf("stats: %v", stats)
+}
+
+// --- Graph Generation Utilities ---
+
+func createLinearGraph(n int) *common.Graph[float64] {
+	b := common.NewGraphBuilder[float64](false).WithVertices(n)
+	for i := 0; i < n-1; i++ {
+		b.AddEdge(i, i+1, 1.0)
+	}
+	return mustBuild(b)
+}
+
+func createGridGraph(rows, cols int) *common.Graph[float64] {
+	b := common.NewGraphBuilder[float64](false).WithVertices(rows * cols)
 	for i := 0; i < rows; i++ {
 		for j := 0; j < cols; j++ {
 			v := i*cols + j
 			if j < cols-1 {
-				u := i*cols + (j + 1)
-				edges = append(edges, common.Edge{U: v, V: u, Weight: 1.0})
+				b.AddEdge(v, i*cols+(j+1), 1.0)
 			}
 			if i < rows-1 {
-				u := (i+1)*cols + j
-				edges = append(edges, common.Edge{U: v, V: u, Weight: 1.0})
+				b.AddEdge(v, (i+1)*cols+j, 1.0)
 			}
 		}
 	}
-	for _, e := range edges {
-		g.Adj[e.U] = append(g.Adj[e.U], e)
-		g.Adj[e.V] = append(g.Adj[e.V], common.Edge{U: e.V, V: e.U, Weight: e.Weight})
-	}
-	g.Edges = edges
-	return g
+	return mustBuild(b)
 }
 
-func createRandomGraph(n, m int, seed int64) *common.Graph {
+func createRandomGraph(n, m int, seed int64) *common.Graph[float64] {
 	r := rand.New(rand.NewSource(seed))
-	g := &common.Graph{
-		N:   n,
-		Adj: make(map[int][]common.Edge),
-	}
-	edges := make([]common.Edge, 0)
+	edges := make([]common.Edge[float64], 0)
 	edgeSet := make(map[string]bool)
 
 	for i := 1; i < n; i++ {
 		parent := r.Intn(i)
 		weight := r.Float64()*10 + 1
-		edges = append(edges, common.Edge{U: parent, V: i, Weight: weight})
+		edges = append(edges, common.Edge[float64]{U: parent, V: i, Weight: weight})
 		edgeSet[fmt.Sprintf("%d-%d", parent, i)] = true
 		edgeSet[fmt.Sprintf("%d-%d", i, parent)] = true
 	}
@@ -300,17 +502,47 @@ func createRandomGraph(n, m int, seed int64) *common.Graph {
 			key2 := fmt.Sprintf("%d-%d", v, u)
 			if !edgeSet[key1] {
 				weight := r.Float64()*10 + 1
-				edges = append(edges, common.Edge{U: u, V: v, Weight: weight})
+				edges = append(edges, common.Edge[float64]{U: u, V: v, Weight: weight})
 				edgeSet[key1] = true
 				edgeSet[key2] = true
 			}
 		}
 	}
 
+	b := common.NewGraphBuilder[float64](false).WithVertices(n)
 	for _, e := range edges {
-		g.Adj[e.U] = append(g.Adj[e.U], e)
-		g.Adj[e.V] = append(g.Adj[e.V], common.Edge{U: e.V, V: e.U, Weight: e.Weight})
+		b.AddEdge(e.U, e.V, e.Weight)
+	}
+	return mustBuild(b)
+}
+
+// createShuffledGridGraph returns a grid with random weights whose vertex ids are a
+// random permutation of the row-major ids, together with the grid position of every vertex.
+func createShuffledGridGraph(rows, cols int, seed int64) (*common.Graph[float64], []common.Coord) {
+	r := rand.New(rand.NewSource(seed))
+	id := r.Perm(rows * cols)
+	coords := make([]common.Coord, rows*cols)
+	b := common.NewGraphBuilder[float64](false).WithVertices(rows * cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			v := id[i*cols+j]
+			coords[v] = common.Coord{X: float64(j), Y: float64(i)}
+			if j < cols-1 {
+				b.AddEdge(v, id[i*cols+j+1], r.Float64()*10+1)
+			}
+			if i < rows-1 {
+				b.AddEdge(v, id[(i+1)*cols+j], r.Float64()*10+1)
+			}
+		}
+	}
+	return mustBuild(b), coords
+}
+
+// mustBuild builds a graph from a generator's valid edge list.
+func mustBuild(b *common.GraphBuilder[float64]) *common.Graph[float64] {
+	g, err := b.Build()
+	if err != nil {
+		panic(err)
 	}
-	g.Edges = edges
 	return g
 }
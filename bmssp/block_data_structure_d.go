@@ -0,0 +1,361 @@
+package bmssp
+
+import (
+	"playground/common"
+	"sort"
+)
+
+// block is a group of at most M entries. Blocks in the same sequence are strictly
+// separated: every value in one block is smaller than every value in the next.
+type block[W common.Weight] struct {
+	items []common.DistEntry[W]
+	upper W // Upper bound of the block's values; only maintained for D1.
+}
+
+type blockLoc[W common.Weight] struct {
+	blk *block[W]
+	idx int
+}
+
+// BlockDataStructureD implements the block-based data structure of Lemma 3.3.
+// D0 holds the blocks created by BatchPrepend, D1 the blocks created by Insert,
+// ordered by upper bound. Insert is O(log(N/M)), BatchPrepend of L entries is
+// O(|L|·log(|L|/M)) and Pull is O(M), all amortized.
+type BlockDataStructureD[W common.Weight] struct {
+	d0    []*block[W] // Front of the sequence is the last element, so prepending is an append.
+	d1    []*block[W] // Sorted by upper bound; the last block always has upper bound B.
+	loc   map[int]blockLoc[W]
+	size  int
+	floor W // Lower bound on every value currently stored.
+	M     int
+	B     W
+}
+
+// Initialize sets up the BlockDataStructureD with a batch size M and upper bound B.
+func (d *BlockDataStructureD[W]) Initialize(M int, B W) {
+	d.M = M
+	d.B = B
+	d.d0 = nil
+	d.d1 = []*block[W]{{upper: B}}
+	d.loc = make(map[int]blockLoc[W])
+	d.size = 0
+	d.floor = B
+}
+
+// Insert adds a vertex with its distance, updating the value if a shorter path is found.
+func (d *BlockDataStructureD[W]) Insert(v int, dist W) {
+	if dist >= d.B {
+		return
+	}
+	if l, ok := d.loc[v]; ok {
+		if dist >= l.blk.items[l.idx].Dist {
+			return
+		}
+		d.remove(v)
+	}
+
+	i := sort.Search(len(d.d1), func(i int) bool { return d.d1[i].upper >= dist })
+	blk := d.d1[i]
+	d.add(blk, common.DistEntry[W]{Vertex: v, Dist: dist})
+	if dist < d.floor {
+		d.floor = dist
+	}
+	if len(blk.items) > d.M {
+		d.splitD1(i)
+	}
+}
+
+// BatchPrepend inserts a list of entries, keeping the smallest distance per vertex.
+// Entries smaller than everything currently stored are prepended as new D0 blocks
+// of at most ceil(M/2) entries; anything else falls back to Insert.
+func (d *BlockDataStructureD[W]) BatchPrepend(entries []common.DistEntry[W]) {
+	if len(entries) == 0 {
+		return
+	}
+	best := make(map[int]W, len(entries))
+	for _, e := range entries {
+		if cur, ok := best[e.Vertex]; !ok || e.Dist < cur {
+			best[e.Vertex] = e.Dist
+		}
+	}
+
+	L := make([]common.DistEntry[W], 0, len(best))
+	minL := d.floor
+	for v, dist := range best {
+		if dist >= d.floor {
+			d.Insert(v, dist)
+			continue
+		}
+		if _, ok := d.loc[v]; ok {
+			d.remove(v)
+		}
+		L = append(L, common.DistEntry[W]{Vertex: v, Dist: dist})
+		if dist < minL {
+			minL = dist
+		}
+	}
+	if len(L) == 0 {
+		return
+	}
+
+	half := (d.M + 1) / 2
+	var blocks []*block[W]
+	var split func(items []common.DistEntry[W])
+	split = func(items []common.DistEntry[W]) {
+		if len(items) <= half {
+			blocks = append(blocks, &block[W]{items: items})
+			return
+		}
+		lo, hi, ok := partitionAtMedian(items)
+		if !ok {
+			blocks = append(blocks, &block[W]{items: items})
+			return
+		}
+		split(lo)
+		split(hi)
+	}
+	split(L)
+
+	// blocks is in ascending order; D0 keeps its front at the end.
+	for i := len(blocks) - 1; i >= 0; i-- {
+		blk := blocks[i]
+		items := blk.items
+		blk.items = make([]common.DistEntry[W], 0, len(items))
+		for _, e := range items {
+			d.add(blk, e)
+		}
+		d.d0 = append(d.d0, blk)
+	}
+	d.floor = minL
+}
+
+// Pull returns the smallest entries, at least M of them unless D holds fewer, and
+// never splits a group of equal keys. Returns (Bi, S') where Bi is the smallest
+// remaining key (or B if none).
+func (d *BlockDataStructureD[W]) Pull() (W, []int) {
+	if d.size == 0 {
+		return d.B, nil
+	}
+	if d.size <= d.M {
+		all := make([]common.DistEntry[W], 0, d.size)
+		collectPrefix(d.d0, true, d.size, &all)
+		collectPrefix(d.d1, false, d.size, &all)
+		Sprime := make([]int, len(all))
+		for i, e := range all {
+			Sprime[i] = e.Vertex
+		}
+		d.Initialize(d.M, d.B)
+		return d.B, Sprime
+	}
+
+	candidates := make([]common.DistEntry[W], 0, 2*d.M)
+	next0 := collectPrefix(d.d0, true, d.M, &candidates)
+	next1 := collectPrefix(d.d1, false, d.M, &candidates)
+
+	// Every uncollected entry is strictly larger than the collected ones of its own
+	// sequence, so the M smallest overall are among the candidates.
+	sel := make([]common.DistEntry[W], len(candidates))
+	copy(sel, candidates)
+	tieKey := common.Inf[W]()
+	if len(sel) > d.M {
+		tieKey = nthSmallest(sel, d.M-1)
+	}
+
+	Sprime := make([]int, 0, d.M)
+	bi := d.B
+	for _, e := range candidates {
+		if e.Dist <= tieKey {
+			Sprime = append(Sprime, e.Vertex)
+		} else if e.Dist < bi {
+			bi = e.Dist
+		}
+	}
+	for _, v := range Sprime {
+		d.remove(v)
+	}
+
+	// The next key may sit in an uncollected block of either sequence.
+	if m := blockMin(d.d0, next0, true); m < bi {
+		bi = m
+	}
+	if m := blockMin(d.d1, next1, false); m < bi {
+		bi = m
+	}
+
+	d.trim()
+	d.floor = bi
+	return bi, Sprime
+}
+
+// IsEmpty checks if the data structure is empty.
+func (d *BlockDataStructureD[W]) IsEmpty() bool {
+	return d.size == 0
+}
+
+func (d *BlockDataStructureD[W]) add(blk *block[W], e common.DistEntry[W]) {
+	d.loc[e.Vertex] = blockLoc[W]{blk: blk, idx: len(blk.items)}
+	blk.items = append(blk.items, e)
+	d.size++
+}
+
+// remove deletes v from its block in O(1) by swapping with the block's last entry.
+func (d *BlockDataStructureD[W]) remove(v int) {
+	l := d.loc[v]
+	items := l.blk.items
+	last := len(items) - 1
+	if l.idx != last {
+		items[l.idx] = items[last]
+		d.loc[items[l.idx].Vertex] = blockLoc[W]{blk: l.blk, idx: l.idx}
+	}
+	l.blk.items = items[:last]
+	delete(d.loc, v)
+	d.size--
+}
+
+// splitD1 splits the D1 block at index i around its median into two blocks.
+func (d *BlockDataStructureD[W]) splitD1(i int) {
+	blk := d.d1[i]
+	items := blk.items
+	lo, hi, ok := partitionAtMedian(items)
+	if !ok {
+		return
+	}
+
+	upper := lo[0].Dist
+	for _, e := range lo {
+		upper = max(upper, e.Dist)
+	}
+	lower := &block[W]{items: make([]common.DistEntry[W], 0, len(lo)), upper: upper}
+	higher := &block[W]{items: make([]common.DistEntry[W], 0, len(hi)), upper: blk.upper}
+	d.size -= len(items)
+	for _, e := range lo {
+		d.add(lower, e)
+	}
+	for _, e := range hi {
+		d.add(higher, e)
+	}
+
+	d.d1 = append(d.d1, nil)
+	copy(d.d1[i+2:], d.d1[i+1:])
+	d.d1[i] = lower
+	d.d1[i+1] = higher
+}
+
+// trim drops empty blocks from the front of both sequences, keeping D1's final block.
+func (d *BlockDataStructureD[W]) trim() {
+	for len(d.d0) > 0 && len(d.d0[len(d.d0)-1].items) == 0 {
+		d.d0 = d.d0[:len(d.d0)-1]
+	}
+	k := 0
+	for k < len(d.d1)-1 && len(d.d1[k].items) == 0 {
+		k++
+	}
+	d.d1 = d.d1[k:]
+}
+
+// collectPrefix appends entries from the front blocks of a sequence until at least M
+// have been gathered, and returns the index of the first block not collected (-1 if none).
+func collectPrefix[W common.Weight](blocks []*block[W], reversed bool, M int, out *[]common.DistEntry[W]) int {
+	start := len(*out)
+	for j := 0; j < len(blocks); j++ {
+		i := j
+		if reversed {
+			i = len(blocks) - 1 - j
+		}
+		if len(*out)-start >= M {
+			return i
+		}
+		*out = append(*out, blocks[i].items...)
+	}
+	return -1
+}
+
+// blockMin returns the smallest value in the first non-empty block at or after index i.
+func blockMin[W common.Weight](blocks []*block[W], i int, reversed bool) W {
+	if i < 0 {
+		return common.Inf[W]()
+	}
+	for i >= 0 && i < len(blocks) {
+		if len(blocks[i].items) > 0 {
+			m := common.Inf[W]()
+			for _, e := range blocks[i].items {
+				m = min(m, e.Dist)
+			}
+			return m
+		}
+		if reversed {
+			i--
+		} else {
+			i++
+		}
+	}
+	return common.Inf[W]()
+}
+
+// partitionAtMedian splits items into two non-empty, strictly separated halves around
+// the median value. ok is false when all values are equal and no such split exists.
+func partitionAtMedian[W common.Weight](items []common.DistEntry[W]) (lo, hi []common.DistEntry[W], ok bool) {
+	tmp := make([]common.DistEntry[W], len(items))
+	copy(tmp, items)
+	median := nthSmallest(tmp, (len(tmp)-1)/2)
+
+	for _, pivotLE := range []bool{true, false} {
+		lo, hi = lo[:0], hi[:0]
+		for _, e := range items {
+			if e.Dist < median || (pivotLE && e.Dist == median) {
+				lo = append(lo, e)
+			} else {
+				hi = append(hi, e)
+			}
+		}
+		if len(lo) > 0 && len(hi) > 0 {
+			return lo, hi, true
+		}
+	}
+	return nil, nil, false
+}
+
+// nthSmallest reorders items so that items[n] holds the n-th smallest value and returns it.
+func nthSmallest[W common.Weight](items []common.DistEntry[W], n int) W {
+	lo, hi := 0, len(items)-1
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		pivot := medianOfThree(items[lo].Dist, items[mid].Dist, items[hi].Dist)
+		i, j := lo, hi
+		for i <= j {
+			for items[i].Dist < pivot {
+				i++
+			}
+			for items[j].Dist > pivot {
+				j--
+			}
+			if i <= j {
+				items[i], items[j] = items[j], items[i]
+				i++
+				j--
+			}
+		}
+		switch {
+		case n <= j:
+			hi = j
+		case n >= i:
+			lo = i
+		default:
+			return items[n].Dist
+		}
+	}
+	return items[n].Dist
+}
+
+func medianOfThree[W common.Weight](a, b, c W) W {
+	if a > b {
+		a, b = b, a
+	}
+	if b > c {
+		b = c
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
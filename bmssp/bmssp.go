@@ -2,50 +2,294 @@ package bmssp
 
 import (
 	"container/heap"
+	"context"
+	"fmt"
 	"math"
+	"math/bits"
 	"playground/common"
 	"slices"
 )
 
-type BMSSPAlgorithm struct {
-	graph *common.Graph
-	dist  map[int]float64
-	l     int
-	B     float64
-	S     []int
+type BMSSPAlgorithm[W common.Weight] struct {
+	source  *common.Graph[W]    // Validated and converted on the first Solve, then dropped.
+	graph   *common.CSRGraph[W] // Graph the recursion runs on; the transpose if reverse is set.
+	reverse bool
+	n       int
+	inf     W
+	l       int
+	B       W
+	S       []int
+	kind    DataStructureKind
+	stats   *Stats // nil unless WithStats was called.
+
+	// Per-vertex state. Entries are only meaningful while the vertex is a member of
+	// the matching StampSet, so starting a new query does not touch all N entries.
+	dist    []W
+	reached common.StampSet  // Vertices with a finite tentative distance.
+	touched []int            // Every vertex added to reached by the current query.
+	pred    []common.Edge[W] // Edge used to reach each vertex; sources have none.
+	hasPred common.StampSet
+	settled common.StampSet // Vertices known to be complete, kept for partial results.
+
+	targets     []int           // If non-empty, the recursion stops once all of them are settled.
+	targetSet   common.StampSet // Targets that are not yet settled.
+	targetsLeft int
+	done        bool // Set once every target is settled.
+
+	levels []levelScratch[W] // Indexed by recursion level.
+	base   baseScratch[W]
+	pivots pivotScratch
+
+	ctx context.Context // Only set while SolveContext is running.
+	err error           // First cancellation error observed by the recursion.
 }
 
-func NewBMSSPAlgorithm(g *common.Graph, l int, B float64, S []int) *BMSSPAlgorithm {
-	dist := make(map[int]float64, g.N)
-	for i := 0; i < g.N; i++ {
-		dist[i] = math.Inf(1)
+// NewBMSSPAlgorithm prepares BMSSP(l, B, S) on g. g is validated and converted into a
+// CSRGraph on the first Solve; later Solves reuse the conversion and do not see
+// changes to g.
+func NewBMSSPAlgorithm[W common.Weight](g *common.Graph[W], l int, B W, S []int) *BMSSPAlgorithm[W] {
+	a := &BMSSPAlgorithm[W]{source: g, inf: common.Inf[W]()}
+	if g != nil {
+		a.n = g.N
 	}
+	a.reset(l, B, S)
+	return a
+}
+
+// NewBMSSPAlgorithmCSR is like NewBMSSPAlgorithm but runs on a CSRGraph, which skips
+// the validation and conversion of a Graph.
+func NewBMSSPAlgorithmCSR[W common.Weight](g *common.CSRGraph[W], l int, B W, S []int) *BMSSPAlgorithm[W] {
+	a := &BMSSPAlgorithm[W]{graph: g, n: g.NumVertices(), inf: common.Inf[W]()}
+	a.reset(l, B, S)
+	return a
+}
+
+// reset prepares a new query, reusing every buffer that is already large enough.
+func (a *BMSSPAlgorithm[W]) reset(l int, B W, S []int) {
+	n := a.n
+	a.l, a.B, a.S = l, B, S
+	if len(a.dist) < n {
+		a.dist = make([]W, n)
+		a.pred = make([]common.Edge[W], n)
+	}
+	a.reached.Reset(n)
+	a.touched = a.touched[:0]
+	a.hasPred.Reset(n)
+	a.settled.Reset(n)
 	for _, s := range S {
-		dist[s] = 0
+		if s >= 0 && s < n {
+			a.setDist(s, 0)
+		}
+	}
+}
+
+// distance returns the tentative distance d̂[v], or infinity if v has not been reached.
+func (a *BMSSPAlgorithm[W]) distance(v int) W {
+	if a.reached.Has(v) {
+		return a.dist[v]
+	}
+	return a.inf
+}
+
+func (a *BMSSPAlgorithm[W]) setDist(v int, d W) {
+	a.dist[v] = d
+	if a.reached.Add(v) {
+		a.touched = append(a.touched, v)
+	}
+}
+
+// setPred records the edge u->v of weight w as the predecessor edge of v.
+func (a *BMSSPAlgorithm[W]) setPred(u, v int, w W) {
+	a.pred[v] = common.Edge[W]{U: u, V: v, Weight: w}
+	a.hasPred.Add(v)
+}
+
+// WithDataStructure selects the implementation of D used by the pull loop.
+func (a *BMSSPAlgorithm[W]) WithDataStructure(kind DataStructureKind) *BMSSPAlgorithm[W] {
+	a.kind = kind
+	return a
+}
+
+// WithTargets makes Solve stop as soon as every target lies in a completed U. The
+// result then holds the distances of the targets and of the vertices settled before
+// them; every other vertex is reported as unreachable. Unreachable targets make the search
+// run to completion.
+func (a *BMSSPAlgorithm[W]) WithTargets(targets ...int) *BMSSPAlgorithm[W] {
+	a.targets = targets
+	return a
+}
+
+// WithReverse turns the search into a single-destination search: it runs on the
+// transpose of the graph, so S acts as a set of destinations and the result holds the
+// distance from every vertex to the nearest of them. Path and Result.Path then lead
+// from a vertex to its destination.
+func (a *BMSSPAlgorithm[W]) WithReverse() *BMSSPAlgorithm[W] {
+	if !a.reverse && a.graph != nil {
+		a.graph = a.graph.Transpose()
+	}
+	a.reverse = true
+	return a
+}
+
+// settle marks v as complete and records whether that was the last pending target.
+func (a *BMSSPAlgorithm[W]) settle(v int) {
+	if a.settled.Add(v) && a.targetsLeft > 0 && a.targetSet.Has(v) {
+		a.targetSet.Remove(v)
+		a.targetsLeft--
+		a.done = a.targetsLeft == 0
+	}
+}
+
+// Solve validates the graph, sources and parameters, then runs BMSSP(l, B, S). It can
+// be called again and starts from scratch each time.
+func (a *BMSSPAlgorithm[W]) Solve() (*common.Result[W], error) {
+	return a.SolveContext(context.Background())
+}
+
+// SolveContext is like Solve but stops once ctx is done. It then returns ctx.Err()
+// together with the distances settled so far; every other vertex is reported as +Inf.
+// Sources count as settled from the start, so they keep distance 0 even if ctx is
+// already done when SolveContext is called. Dijkstra follows the same contract.
+func (a *BMSSPAlgorithm[W]) SolveContext(ctx context.Context) (*common.Result[W], error) {
+	if err := a.validateGraph(); err != nil {
+		return nil, err
 	}
-	return &BMSSPAlgorithm{
-		graph: g,
-		dist:  dist,
-		l:     l,
-		B:     B,
-		S:     S,
+	a.reset(a.l, a.B, a.S)
+	if err := a.validateQuery(); err != nil {
+		return nil, err
 	}
+	err := a.run(ctx)
+	return a.result(), err
 }
 
-func (a *BMSSPAlgorithm) Solve() (map[int]float64, error) {
-	a.bmsspRecursive(a.l, a.B, a.S)
-	return a.dist, nil
+// run executes BMSSP(l, B, S) on a validated query.
+func (a *BMSSPAlgorithm[W]) run(ctx context.Context) error {
+	a.done, a.targetsLeft = false, 0
+	if len(a.targets) > 0 {
+		a.targetSet.Reset(a.n)
+		for _, v := range a.targets {
+			if a.targetSet.Add(v) {
+				a.targetsLeft++
+			}
+		}
+	}
+	for _, s := range a.S {
+		a.settle(s)
+	}
+	if a.stats != nil {
+		*a.stats = Stats{RecursionCalls: make([]int, a.l+1)}
+	}
+	for len(a.levels) <= a.l {
+		a.levels = append(a.levels, levelScratch[W]{})
+	}
+
+	a.ctx, a.err = ctx, nil
+	defer func() { a.ctx = nil }()
+	if !a.done {
+		a.bmsspRecursive(a.l, a.B, a.S)
+	}
+	if a.stopped() {
+		a.dropUnsettled()
+	}
+	return a.err
 }
 
-// k = floor(log(n)^(1/3)), t = floor(log(n)^(2/3)), each ≥ 1
-func (a *BMSSPAlgorithm) kt() (int, int) {
-	n := float64(a.graph.N)
+// result copies the tentative distances and predecessor edges into a Result.
+func (a *BMSSPAlgorithm[W]) result() *common.Result[W] {
+	dist := make([]W, a.n)
+	pred := make([]common.Edge[W], a.n)
+	for v := range dist {
+		dist[v] = a.distance(v)
+		if a.hasPred.Has(v) {
+			pred[v] = a.pred[v]
+		} else {
+			pred[v] = common.Edge[W]{U: -1, V: v}
+		}
+	}
+	if a.reverse {
+		return common.NewReverseResult(dist, pred)
+	}
+	return common.NewResult(dist, pred)
+}
+
+// cancelled records and reports whether the running solve has been cancelled.
+func (a *BMSSPAlgorithm[W]) cancelled() bool {
+	if a.err == nil && a.ctx != nil {
+		a.err = a.ctx.Err()
+	}
+	return a.err != nil
+}
+
+// stopped reports whether the recursion has to unwind, either because the solve was
+// cancelled or because every target has been settled.
+func (a *BMSSPAlgorithm[W]) stopped() bool {
+	return a.cancelled() || a.done
+}
+
+// dropUnsettled resets every tentative distance to +Inf after a solve that stopped early.
+// It only visits the vertices the query reached, so an early stop costs no O(N) scan.
+func (a *BMSSPAlgorithm[W]) dropUnsettled() {
+	for _, v := range a.touched {
+		if a.reached.Has(v) && !a.settled.Has(v) {
+			a.reached.Remove(v)
+			a.hasPred.Remove(v)
+		}
+	}
+}
+
+// validateGraph validates the source Graph and converts it into the CSRGraph the
+// recursion runs on, transposing it for a reverse search. Once that succeeded it does
+// nothing.
+func (a *BMSSPAlgorithm[W]) validateGraph() error {
+	if a.graph != nil {
+		return nil
+	}
+	csr, err := common.NewCSRGraph(a.source)
+	if err != nil {
+		return fmt.Errorf("bmssp: %w", err)
+	}
+	if a.reverse {
+		csr = csr.Transpose()
+	}
+	a.source, a.graph, a.n = nil, csr, csr.NumVertices()
+	return nil
+}
+
+func (a *BMSSPAlgorithm[W]) validateQuery() error {
+	if err := common.ValidateSources(a.graph, a.S); err != nil {
+		return fmt.Errorf("bmssp: %w", err)
+	}
+	if err := common.ValidateTargets(a.graph, a.targets); err != nil {
+		return fmt.Errorf("bmssp: %w", err)
+	}
+	if a.l < 0 {
+		return fmt.Errorf("bmssp: %w", &common.ParameterError{Name: "l", Value: a.l, Reason: "must be non-negative"})
+	}
+	if a.B != a.B {
+		return fmt.Errorf("bmssp: %w", &common.ParameterError{Name: "B", Value: a.B, Reason: "must not be NaN"})
+	}
+	if a.kind != HeapD && a.kind != BlockD {
+		return fmt.Errorf("bmssp: %w", &common.ParameterError{Name: "data structure", Value: a.kind, Reason: "unknown kind"})
+	}
+
+	// The pull loop computes k·2^{l·t}, which has to fit in an int.
+	k, t := a.kt()
+	if a.l*t >= bits.UintSize-1 || k > math.MaxInt>>uint(a.l*t) {
+		return fmt.Errorf("bmssp: %w", &common.ParameterError{Name: "l", Value: a.l, Reason: fmt.Sprintf("k·2^(l·t) overflows int with k=%d, t=%d", k, t)})
+	}
+	return nil
+}
+
+// k = floor(log2(n)^(1/3)), t = floor(log2(n)^(2/3)), each ≥ 1. The paper's logarithms
+// are binary; topLevelDepth relies on that for k·2^{lt} ≥ n.
+func (a *BMSSPAlgorithm[W]) kt() (int, int) {
+	n := float64(a.n)
 	if n < 2 {
 		n = 2
 	}
-	ln := math.Log(n)
-	k := int(math.Floor(math.Pow(ln, 1.0/3.0)))
-	t := int(math.Floor(math.Pow(ln, 2.0/3.0)))
+	lg := math.Log2(n)
+	k := int(math.Floor(math.Pow(lg, 1.0/3.0)))
+	t := int(math.Floor(math.Pow(lg, 2.0/3.0)))
 	if k < 1 {
 		k = 1
 	}
@@ -55,20 +299,27 @@ func (a *BMSSPAlgorithm) kt() (int, int) {
 	return k, t
 }
 
-func (a *BMSSPAlgorithm) bmsspRecursive(l int, B float64, S []int) (float64, []int) {
+func (a *BMSSPAlgorithm[W]) bmsspRecursive(l int, B W, S []int) (W, []int) {
+	if a.stats != nil {
+		a.stats.RecursionCalls[l]++
+	}
 	if l == 0 {
 		return a.baseCaseSingletonOrSplit(B, S)
 	}
 
-	P, W := a.findPivots(B, S)
+	// Only one call per level is active at a time, so each level owns its scratch.
+	lv := &a.levels[l]
+	lv.seen.Reset(a.n)
+	U := lv.U[:0]
+	defer func() { lv.U = U[:0] }()
+
+	P := a.findPivots(B, S, lv)
 
 	// No pivots ⇒ successful execution: B' = B, add W' = { x in W : d̂[x] < B }.
 	if len(P) == 0 {
-		U := make([]int, 0, len(W))
-		seen := make(map[int]bool, len(W))
-		for v, dv := range W {
-			if dv < B && !seen[v] {
-				seen[v] = true
+		for i, v := range lv.W {
+			if lv.WDist[i] < B && lv.seen.Add(v) {
+				a.settle(v)
 				U = append(U, v)
 			}
 		}
@@ -78,45 +329,57 @@ func (a *BMSSPAlgorithm) bmsspRecursive(l int, B float64, S []int) (float64, []i
 
 	k, t := a.kt()
 	M := 1 << uint((l-1)*t)
-	var D DataStructureD
+	D := lv.queue(a.kind)
+	if a.stats != nil {
+		D = countingQueue[W]{PullQueue: D, stats: a.stats}
+	}
 	D.Initialize(M, B)
 
 	for _, x := range P {
-		if d, exists := a.dist[x]; exists {
-			D.Insert(x, d)
-		}
+		D.Insert(x, a.distance(x))
 	}
 
 	threshold := k * (1 << uint(l*t))
-	U := make([]int, 0, threshold)
-	seenU := make(map[int]bool)
 	lastBip := B
 
 	for len(U) < threshold && !D.IsEmpty() {
+		if a.stopped() {
+			break
+		}
 		Bi, Si := D.Pull()
 		Bip, Ui := a.bmsspRecursive(l-1, Bi, Si)
 		lastBip = Bip
 
 		// Dedup Ui before adding to U
 		for _, u := range Ui {
-			if !seenU[u] {
-				seenU[u] = true
+			if lv.seen.Add(u) {
 				U = append(U, u)
 			}
 		}
 
 		// After recursion, do <= relaxations but NEVER write distances ≥ B
-		K := make([]common.DistEntry, 0)
+		K := lv.K[:0]
+		// Vertices already in U are complete, so an equal-length relaxation into them
+		// (e.g. around a zero-weight cycle) must not put them back into D.
 		for _, u := range Ui {
-			for _, e := range a.graph.Adj[u] {
-				v := e.V
-				newDist := a.dist[u] + e.Weight
-				if newDist < B && newDist <= a.dist[v] {
-					a.dist[v] = newDist
+			if a.stats != nil {
+				a.stats.Relaxations += a.graph.Degree(u)
+			}
+			du := a.distance(u)
+			targets, weights := a.graph.Neighbors(u)
+			for i, t := range targets {
+				v, w := int(t), weights[i]
+				newDist := common.AddWeights(du, w)
+				dv := a.distance(v)
+				if newDist < B && newDist <= dv && !lv.seen.Has(v) {
+					if newDist < dv {
+						a.setPred(u, v, w)
+					}
+					a.setDist(v, newDist)
 					if newDist >= Bi { // Bi ≤ newDist < B
 						D.Insert(v, newDist)
 					} else if newDist >= Bip { // Bip ≤ newDist < Bi
-						K = append(K, common.DistEntry{Vertex: v, Dist: newDist})
+						K = append(K, common.DistEntry[W]{Vertex: v, Dist: newDist})
 					}
 				}
 			}
@@ -124,20 +387,30 @@ func (a *BMSSPAlgorithm) bmsspRecursive(l int, B float64, S []int) (float64, []i
 
 		// Also include sources from Si whose key now falls in [Bip, Bi)
 		for _, sNode := range Si {
-			if d, ok := a.dist[sNode]; ok && d < B && d >= Bip && d < Bi {
-				K = append(K, common.DistEntry{Vertex: sNode, Dist: d})
+			if d := a.distance(sNode); d < B && d >= Bip && d < Bi {
+				K = append(K, common.DistEntry[W]{Vertex: sNode, Dist: d})
 			}
 		}
 
 		D.BatchPrepend(K)
+		lv.K = K
+	}
+	if a.stats != nil && !a.stopped() && len(U) >= threshold && !D.IsEmpty() {
+		a.stats.ThresholdExits++
+	}
+
+	// A stopped recursion returns early, so nothing beyond U can be trusted. Once the
+	// targets are settled the callers above unwind without further pulls.
+	if a.stopped() {
+		return B, U
 	}
 
-	Bp := math.Min(lastBip, B)
+	Bp := min(lastBip, B)
 
 	// Add W' = { x in W : d̂[x] < B' } (dedup against U)
-	for v, dv := range W {
-		if dv < Bp && !seenU[v] {
-			seenU[v] = true
+	for i, v := range lv.W {
+		if lv.WDist[i] < Bp && lv.seen.Add(v) {
+			a.settle(v)
 			U = append(U, v)
 		}
 	}
@@ -147,71 +420,89 @@ func (a *BMSSPAlgorithm) bmsspRecursive(l int, B float64, S []int) (float64, []i
 }
 
 // Base case: enforce singleton, split otherwise
-func (a *BMSSPAlgorithm) baseCaseSingletonOrSplit(B float64, S []int) (float64, []int) {
+func (a *BMSSPAlgorithm[W]) baseCaseSingletonOrSplit(B W, S []int) (W, []int) {
 	if len(S) == 1 {
 		return a.baseCase(B, S[0])
 	}
+	lv := &a.levels[0]
+	lv.seen.Reset(a.n)
 	minBp := B
-	seen := map[int]bool{}
-	U := []int{}
+	U := lv.U[:0]
 	for _, s := range S {
 		Bpi, Ui := a.baseCase(B, s)
 		if Bpi < minBp {
 			minBp = Bpi
 		}
 		for _, u := range Ui {
-			if !seen[u] {
-				seen[u] = true
+			if lv.seen.Add(u) {
 				U = append(U, u)
 			}
 		}
 	}
 	slices.Sort(U)
+	lv.U = U
 	return minBp, U
 }
 
 // Robust single-source bounded Dijkstra (lazy decrease-key) that NEVER writes dist ≥ B
-func (a *BMSSPAlgorithm) baseCase(B float64, s int) (float64, []int) {
-	pq := make(common.PriorityQueue, 0)
-	heap.Init(&pq)
-	heap.Push(&pq, &common.DistEntry{Vertex: s, Dist: a.dist[s]})
+func (a *BMSSPAlgorithm[W]) baseCase(B W, s int) (W, []int) {
+	if a.stats != nil {
+		a.stats.BaseCases++
+	}
+	bs := &a.base
+	pq := bs.resetQueue()
+	heap.Push(pq, bs.entries.Get(s, a.distance(s)))
 
-	U := make([]int, 0)
-	seenU := make(map[int]bool)
+	bs.seen.Reset(a.n)
+	U := bs.U[:0]
+	defer func() { bs.U = U[:0] }()
 
 	for pq.Len() > 0 {
-		entry := heap.Pop(&pq).(*common.DistEntry)
+		if a.stopped() {
+			return B, U
+		}
+		entry := heap.Pop(pq).(*common.DistEntry[W])
 		u := entry.Vertex
 
 		// Skip stale entries
-		if entry.Dist != a.dist[u] {
+		if entry.Dist != a.distance(u) {
 			continue
 		}
 		// If the smallest key is ≥ B, we're at the boundary
 		if entry.Dist >= B {
-			heap.Push(&pq, entry) // put back so pq[0] is the boundary key
+			heap.Push(pq, entry) // put back so pq[0] is the boundary key
 			break
 		}
 
-		if !seenU[u] {
-			seenU[u] = true
-			U = append(U, u)
+		// A settled vertex was already relaxed with this same distance.
+		if !bs.seen.Add(u) {
+			continue
 		}
+		a.settle(u)
+		U = append(U, u)
 
-		for _, e := range a.graph.Adj[u] {
-			v := e.V
-			newDist := entry.Dist + e.Weight
+		if a.stats != nil {
+			a.stats.Relaxations += a.graph.Degree(u)
+		}
+		targets, weights := a.graph.Neighbors(u)
+		for i, t := range targets {
+			v, w := int(t), weights[i]
+			newDist := common.AddWeights(entry.Dist, w)
+			dv := a.distance(v)
 			// Only write & push if strictly below B
-			if newDist < B && newDist <= a.dist[v] {
-				a.dist[v] = newDist
-				heap.Push(&pq, &common.DistEntry{Vertex: v, Dist: newDist})
+			if newDist < B && newDist <= dv {
+				if newDist < dv {
+					a.setPred(u, v, w)
+				}
+				a.setDist(v, newDist)
+				heap.Push(pq, bs.entries.Get(v, newDist))
 			}
 		}
 	}
 
 	Bp := B
 	if pq.Len() > 0 {
-		minBoundaryDist := pq[0].Dist
+		minBoundaryDist := (*pq)[0].Dist
 		if minBoundaryDist < Bp {
 			Bp = minBoundaryDist
 		}
@@ -219,81 +510,79 @@ func (a *BMSSPAlgorithm) baseCase(B float64, s int) (float64, []int) {
 	return Bp, U
 }
 
-// Pivot-finding (k-step <=-relaxations bounded by B, equality forest, roots with size ≥ k)
-func (a *BMSSPAlgorithm) findPivots(B float64, S []int) ([]int, map[int]float64) {
+// Pivot-finding (k-step <=-relaxations bounded by B, equality forest, roots with size ≥ k).
+// W and the snapshot of its distances are left in lv; the returned pivots alias lv.P or S.
+// The weight parameter is renamed here because W names the vertex set of the paper.
+func (a *BMSSPAlgorithm[Wt]) findPivots(B Wt, S []int, lv *levelScratch[Wt]) []int {
 	kParam, _ := a.kt()
+	ps := &a.pivots
+	n := a.n
 
-	WMap := make(map[int]bool, len(S))
+	ps.reset(n)
+	W := lv.W[:0]
 	for _, sNode := range S {
-		WMap[sNode] = true
+		if ps.inW.Add(sNode) {
+			W = append(W, sNode)
+		}
 	}
 
-	pred := make(map[int]int)
-
 	// BFS-like k rounds of <= relaxations within bound B
-	WFrontiers := make([][]int, kParam+1)
-	WFrontiers[0] = S
+	frontier := append(ps.frontier[:0], S...)
+	next := ps.next[:0]
 	for i := 1; i <= kParam; i++ {
-		WFrontiers[i] = make([]int, 0)
-		frontierMap := make(map[int]bool)
-		for _, u := range WFrontiers[i-1] {
-			for _, e := range a.graph.Adj[u] {
-				v := e.V
-				newDist := a.dist[u] + e.Weight
-				if newDist < B && newDist <= a.dist[v] {
-					a.dist[v] = newDist
-					pred[v] = u
-					WMap[v] = true
-					if !frontierMap[v] {
-						WFrontiers[i] = append(WFrontiers[i], v)
-						frontierMap[v] = true
+		next = next[:0]
+		ps.inFrontier.Reset(n)
+		for _, u := range frontier {
+			if a.stats != nil {
+				a.stats.Relaxations += a.graph.Degree(u)
+			}
+			du := a.distance(u)
+			targets, weights := a.graph.Neighbors(u)
+			for i, t := range targets {
+				v, w := int(t), weights[i]
+				newDist := common.AddWeights(du, w)
+				dv := a.distance(v)
+				if newDist < B && newDist <= dv {
+					if newDist < dv {
+						a.setPred(u, v, w)
+					}
+					a.setDist(v, newDist)
+					ps.pred[v] = u
+					ps.hasPred.Add(v)
+					if ps.inW.Add(v) {
+						W = append(W, v)
+					}
+					if ps.inFrontier.Add(v) {
+						next = append(next, v)
 					}
 				}
 			}
 		}
+		frontier, next = next, frontier
 	}
+	ps.frontier, ps.next = frontier, next
 
-	WDistMap := make(map[int]float64, len(WMap))
-	for v := range WMap {
-		WDistMap[v] = a.dist[v]
+	WDist := lv.WDist[:0]
+	for _, v := range W {
+		WDist = append(WDist, a.distance(v))
 	}
+	lv.W, lv.WDist = W, WDist
 
 	// If W is too large, choose all S as pivots
-	if len(WDistMap) > kParam*len(S) {
-		return S, WDistMap
-	}
-
-	// Build equality-forest and compute subtree sizes
-	children := make(map[int][]int)
-	for v, u := range pred {
-		if WMap[v] && WMap[u] {
-			children[u] = append(children[u], v)
-		}
+	if len(W) > kParam*len(S) {
+		a.countPivots(len(S), len(S))
+		return S
 	}
 
-	treeSizes := make(map[int]int)
-	var dfs func(u int) int
-	dfs = func(u int) int {
-		if size, ok := treeSizes[u]; ok {
-			return size
-		}
-		size := 1
-		for _, vv := range children[u] {
-			size += dfs(vv)
-		}
-		treeSizes[u] = size
-		return size
-	}
+	P := ps.forestRoots(S, W, kParam, lv.P[:0])
+	lv.P = P
+	a.countPivots(len(P), len(S))
+	return P
+}
 
-	P := make([]int, 0)
-	for _, u := range S {
-		if _, ok := treeSizes[u]; !ok {
-			dfs(u)
-		}
-		if treeSizes[u] >= kParam {
-			P = append(P, u)
-		}
+func (a *BMSSPAlgorithm[W]) countPivots(pivots, sources int) {
+	if a.stats != nil {
+		a.stats.Pivots += pivots
+		a.stats.PivotSources += sources
 	}
-
-	return P, WDistMap
 }
@@ -1,13 +1,19 @@
 package bmssp
 
 import (
+	"context"
+	"errors"
+	"maps"
 	"math"
+	"math/rand"
 	"playground/common"
+	"playground/dijkstra"
+	"slices"
 	"testing"
 )
 
 func TestDataStructureD_BasicOperations(t *testing.T) {
-	var d DataStructureD
+	var d DataStructureD[float64]
 	d.Initialize(2, 100.0) // M=2, B=100.0
 
 	if !d.IsEmpty() {
@@ -45,10 +51,10 @@ func TestDataStructureD_BasicOperations(t *testing.T) {
 }
 
 func TestDataStructureD_BatchPrepend(t *testing.T) {
-	var d DataStructureD
+	var d DataStructureD[float64]
 	d.Initialize(10, 100.0)
 
-	entries := []common.DistEntry{
+	entries := []common.DistEntry[float64]{
 		{Vertex: 1, Dist: 5.0},
 		{Vertex: 2, Dist: 3.0},
 		{Vertex: 3, Dist: 7.0},
@@ -74,7 +80,7 @@ func TestDataStructureD_BatchPrepend(t *testing.T) {
 }
 
 func TestDataStructureD_TieDrain_NoSplit(t *testing.T) {
-	var d DataStructureD
+	var d DataStructureD[float64]
 	d.Initialize(1, 1000) // M=1 on purpose
 
 	// three equal keys, then a larger one
@@ -92,11 +98,127 @@ func TestDataStructureD_TieDrain_NoSplit(t *testing.T) {
 	}
 }
 
+func TestDataStructureD_TieDrain_LastKey(t *testing.T) {
+	var d DataStructureD[float64]
+	d.Initialize(2, 1000)
+
+	// The batch fills up inside the tie at 3, which must be drained as a whole
+	// so that every pulled key stays below Bi.
+	d.Insert(10, 1)
+	d.Insert(11, 3)
+	d.Insert(12, 3)
+	d.Insert(13, 3)
+	d.Insert(20, 5)
+
+	Bi, S := d.Pull()
+	if !slices.Equal(slices.Sorted(slices.Values(S)), []int{10, 11, 12, 13}) {
+		t.Fatalf("expected to drain the tie at 3, got %v", S)
+	}
+	if Bi != 5 {
+		t.Fatalf("expected Bi=5 (next strictly larger), got %v", Bi)
+	}
+}
+
+func TestDataStructureD_IntegerTiesAreExact(t *testing.T) {
+	var d DataStructureD[int64]
+	d.Initialize(1, common.Inf[int64]())
+
+	// As float64 these three keys would all round to 1<<53 and form one tie.
+	d.Insert(1, 1<<53+1)
+	d.Insert(2, 1<<53)
+	d.Insert(3, 1<<53)
+
+	Bi, S := d.Pull()
+	if !slices.Equal(slices.Sorted(slices.Values(S)), []int{2, 3}) {
+		t.Fatalf("expected to drain the tie {2, 3}, got %v", S)
+	}
+	if Bi != 1<<53+1 {
+		t.Fatalf("expected Bi=%d, got %d", int64(1<<53+1), Bi)
+	}
+}
+
+func TestBlockDataStructureD_MatchesHeap(t *testing.T) {
+	for seed := int64(1); seed <= 50; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		M := 1 + r.Intn(8)
+		B := 1000.0
+
+		var heapD DataStructureD[float64]
+		var blockD BlockDataStructureD[float64]
+		heapD.Initialize(M, B)
+		blockD.Initialize(M, B)
+
+		floor := 0.0
+		for step := 0; step < 300; step++ {
+			switch op := r.Intn(4); {
+			case op <= 1:
+				v, dist := r.Intn(100), floor+float64(r.Intn(50))
+				heapD.Insert(v, dist)
+				blockD.Insert(v, dist)
+			case op == 2 && floor > 0:
+				// BatchPrepend values must be smaller than everything in D.
+				entries := make([]common.DistEntry[float64], r.Intn(3*M))
+				for i := range entries {
+					entries[i] = common.DistEntry[float64]{Vertex: r.Intn(100), Dist: float64(r.Intn(int(floor)))}
+				}
+				heapD.BatchPrepend(entries)
+				blockD.BatchPrepend(entries)
+			default:
+				hb, hs := heapD.Pull()
+				bb, bs := blockD.Pull()
+				slices.Sort(hs)
+				slices.Sort(bs)
+				if hb != bb || !slices.Equal(hs, bs) {
+					t.Fatalf("seed %d step %d: heap pulled (%v, %v), block pulled (%v, %v)", seed, step, hb, hs, bb, bs)
+				}
+				floor = hb
+			}
+			if heapD.IsEmpty() != blockD.IsEmpty() {
+				t.Fatalf("seed %d step %d: IsEmpty mismatch", seed, step)
+			}
+		}
+	}
+}
+
+func TestBlockDataStructureD_TieDrain_NoSplit(t *testing.T) {
+	var d BlockDataStructureD[float64]
+	d.Initialize(1, 1000)
+
+	d.Insert(10, 0)
+	d.Insert(11, 0)
+	d.Insert(12, 0)
+	d.Insert(20, 5)
+
+	Bi, S := d.Pull()
+	if len(S) != 3 {
+		t.Fatalf("expected to drain all ties, got %d elems: %v", len(S), S)
+	}
+	if Bi != 5 {
+		t.Fatalf("expected Bi=5 (next strictly larger), got %v", Bi)
+	}
+}
+
+func TestBMSSP_BlockDataStructure(t *testing.T) {
+	for seed := int64(1); seed <= 10; seed++ {
+		g := createRandomGraph(300, 900, seed)
+		want, _ := NewBMSSPAlgorithm(g, 3, 1000.0, []int{0}).Solve()
+		got, err := NewBMSSPAlgorithm(g, 3, 1000.0, []int{0}).WithDataStructure(BlockD).Solve()
+		if err != nil {
+			t.Fatalf("seed %d: Solve() returned an error: %v", seed, err)
+		}
+		for v := 0; v < g.N; v++ {
+			if got.Dist(v) != want.Dist(v) {
+				t.Fatalf("seed %d: vertex %d: heap D gave %f, block D gave %f", seed, v, want.Dist(v), got.Dist(v))
+			}
+		}
+	}
+}
+
 func TestBMSSP_FractionalWeights(t *testing.T) {
-	g := &common.Graph{N: 6, Adj: make(map[int][]common.Edge)}
+	g := &common.Graph[float64]{N: 6, Adj: make(map[int][]common.Edge[float64])}
 	add := func(u, v int, w float64) {
-		g.Adj[u] = append(g.Adj[u], common.Edge{U: u, V: v, Weight: w})
-		g.Adj[v] = append(g.Adj[v], common.Edge{U: v, V: u, Weight: w})
+		g.Adj[u] = append(g.Adj[u], common.Edge[float64]{U: u, V: v, Weight: w})
+		g.Adj[v] = append(g.Adj[v], common.Edge[float64]{U: v, V: u, Weight: w})
 	}
 	for i := 0; i < 5; i++ {
 		add(i, i+1, 0.6)
@@ -107,24 +229,24 @@ func TestBMSSP_FractionalWeights(t *testing.T) {
 
 	for i := 0; i < 6; i++ {
 		want := float64(i) * 0.6
-		if math.Abs(dist[i]-want) > 1e-9 {
-			t.Fatalf("v%d: want %.1f, got %v", i, want, dist[i])
+		if math.Abs(dist.Dist(i)-want) > 1e-9 {
+			t.Fatalf("v%d: want %.1f, got %v", i, want, dist.Dist(i))
 		}
 	}
 }
 
 func TestBMSSP_NoPivots_Successful(t *testing.T) {
 	// line of 6 with weight 1; B so tight no relax happens
-	g := &common.Graph{N: 6, Adj: make(map[int][]common.Edge)}
+	g := &common.Graph[float64]{N: 6, Adj: make(map[int][]common.Edge[float64])}
 	for i := 0; i < 5; i++ {
-		g.Adj[i] = append(g.Adj[i], common.Edge{U: i, V: i + 1, Weight: 1})
-		g.Adj[i+1] = append(g.Adj[i+1], common.Edge{U: i + 1, V: i, Weight: 1})
+		g.Adj[i] = append(g.Adj[i], common.Edge[float64]{U: i, V: i + 1, Weight: 1})
+		g.Adj[i+1] = append(g.Adj[i+1], common.Edge[float64]{U: i + 1, V: i, Weight: 1})
 	}
 	algo := NewBMSSPAlgorithm(g, 3, 0.5, []int{0})
 	dist, _ := algo.Solve()
 
-	if dist[0] != 0 || !math.IsInf(dist[1], 1) {
-		t.Fatalf("expected only source reached under tight B; got dist=%v", dist)
+	if dist.Dist(0) != 0 || !math.IsInf(dist.Dist(1), 1) {
+		t.Fatalf("expected only source reached under tight B; got dist=%v", dist.Distances())
 	}
 }
 
@@ -140,8 +262,8 @@ func TestBMSSP_SingleSource(t *testing.T) {
 
 	expectedDist := map[int]float64{0: 0, 1: 1, 2: 2, 3: 3, 4: 4}
 	for v, expected := range expectedDist {
-		if math.Abs(dist[v]-expected) > 1e-9 {
-			t.Errorf("Vertex %d: expected dist=%f, got %f", v, expected, dist[v])
+		if math.Abs(dist.Dist(v)-expected) > 1e-9 {
+			t.Errorf("Vertex %d: expected dist=%f, got %f", v, expected, dist.Dist(v))
 		}
 	}
 }
@@ -156,23 +278,23 @@ func TestBMSSP_MultiSource(t *testing.T) {
 	}
 
 	// Middle vertex should have distance 2 from nearest source
-	if dist[2] != 2.0 {
-		t.Errorf("Middle vertex distance incorrect: expected 2, got %f", dist[2])
+	if dist.Dist(2) != 2.0 {
+		t.Errorf("Middle vertex distance incorrect: expected 2, got %f", dist.Dist(2))
 	}
 }
 
 func TestBMSSP_DisconnectedGraph(t *testing.T) {
-	g := &common.Graph{
+	g := &common.Graph[float64]{
 		N:   6,
-		Adj: make(map[int][]common.Edge),
+		Adj: make(map[int][]common.Edge[float64]),
 	}
-	edges := []common.Edge{
-		{0, 1, 1.0}, {1, 2, 1.0}, // Component 1
-		{3, 4, 1.0}, {4, 5, 1.0}, // Component 2
+	edges := []common.Edge[float64]{
+		{U: 0, V: 1, Weight: 1.0}, {U: 1, V: 2, Weight: 1.0}, // Component 1
+		{U: 3, V: 4, Weight: 1.0}, {U: 4, V: 5, Weight: 1.0}, // Component 2
 	}
 	for _, e := range edges {
 		g.Adj[e.U] = append(g.Adj[e.U], e)
-		g.Adj[e.V] = append(g.Adj[e.V], common.Edge{U: e.V, V: e.U, Weight: e.Weight})
+		g.Adj[e.V] = append(g.Adj[e.V], common.Edge[float64]{U: e.V, V: e.U, Weight: e.Weight})
 	}
 	g.Edges = edges
 
@@ -182,10 +304,10 @@ func TestBMSSP_DisconnectedGraph(t *testing.T) {
 		t.Fatalf("Solve() returned an error: %v", err)
 	}
 
-	if math.IsInf(dist[1], 1) || math.IsInf(dist[2], 1) {
+	if math.IsInf(dist.Dist(1), 1) || math.IsInf(dist.Dist(2), 1) {
 		t.Error("Connected vertices have infinite distance")
 	}
-	if !math.IsInf(dist[3], 1) || !math.IsInf(dist[4], 1) || !math.IsInf(dist[5], 1) {
+	if !math.IsInf(dist.Dist(3), 1) || !math.IsInf(dist.Dist(4), 1) || !math.IsInf(dist.Dist(5), 1) {
 		t.Error("Disconnected vertices have finite distance")
 	}
 }
@@ -200,8 +322,8 @@ func TestBMSSP_CycleGraph(t *testing.T) {
 
 	expected := map[int]float64{0: 0, 1: 1, 2: 3, 3: 4}
 	for v, exp := range expected {
-		if math.Abs(dist[v]-exp) > 1e-9 {
-			t.Errorf("Vertex %d: expected dist=%f, got %f", v, exp, dist[v])
+		if math.Abs(dist.Dist(v)-exp) > 1e-9 {
+			t.Errorf("Vertex %d: expected dist=%f, got %f", v, exp, dist.Dist(v))
 		}
 	}
 }
@@ -216,7 +338,7 @@ func TestBMSSP_DifferentRecursionDepths(t *testing.T) {
 		}
 
 		for i := 0; i < g.N; i++ {
-			if math.IsInf(dist[i], 1) {
+			if math.IsInf(dist.Dist(i), 1) {
 				t.Errorf("l=%d: Vertex %d unreachable", l, i)
 			}
 		}
@@ -234,8 +356,8 @@ func TestBMSSP_BoundaryConstraint(t *testing.T) {
 
 	// Check within/beyond the boundary behavior.
 	for i := 0; i < 10; i++ {
-		if float64(i) < B && dist[i] != float64(i) {
-			t.Errorf("Vertex %d within boundary has wrong distance: got %f, want %f", i, dist[i], float64(i))
+		if float64(i) < B && dist.Dist(i) != float64(i) {
+			t.Errorf("Vertex %d within boundary has wrong distance: got %f, want %f", i, dist.Dist(i), float64(i))
 		}
 	}
 }
@@ -251,46 +373,744 @@ func TestBMSSP_LargeSparseGraph(t *testing.T) {
 	}
 
 	for i := 0; i < n; i++ {
-		if math.IsInf(dist[i], 1) {
+		if math.IsInf(dist.Dist(i), 1) {
 			t.Errorf("Vertex %d has infinite distance", i)
 		}
 	}
 }
 
+func TestBMSSP_PathReconstruction(t *testing.T) {
+	g := createCycleGraph()
+	algo := NewBMSSPAlgorithm(g, 2, 100.0, []int{0})
+	dist, err := algo.Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+
+	vertices, edges, ok := algo.Path(3)
+	if !ok {
+		t.Fatal("expected a path to vertex 3")
+	}
+	wantVertices := []int{0, 1, 2, 3}
+	if len(vertices) != len(wantVertices) {
+		t.Fatalf("expected path %v, got %v", wantVertices, vertices)
+	}
+	for i := range wantVertices {
+		if vertices[i] != wantVertices[i] {
+			t.Fatalf("expected path %v, got %v", wantVertices, vertices)
+		}
+	}
+	if len(edges) != len(vertices)-1 {
+		t.Fatalf("expected %d edges, got %d", len(vertices)-1, len(edges))
+	}
+	total := 0.0
+	for i, e := range edges {
+		if e.U != vertices[i] || e.V != vertices[i+1] {
+			t.Errorf("edge %d (%d->%d) does not match vertex sequence %v", i, e.U, e.V, vertices)
+		}
+		total += e.Weight
+	}
+	if math.Abs(total-dist.Dist(3)) > 1e-9 {
+		t.Errorf("path weight %f does not match dist %f", total, dist.Dist(3))
+	}
+
+	vertices, edges, ok = algo.Path(0)
+	if !ok || len(vertices) != 1 || vertices[0] != 0 || len(edges) != 0 {
+		t.Errorf("expected trivial path for source, got %v %v %v", vertices, edges, ok)
+	}
+}
+
+func TestBMSSP_PathUnreachable(t *testing.T) {
+	g := createLinearGraph(10)
+	algo := NewBMSSPAlgorithm(g, 2, 5.0, []int{0})
+	if _, err := algo.Solve(); err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+
+	if _, _, ok := algo.Path(8); ok {
+		t.Error("expected no path to a vertex beyond the boundary")
+	}
+	if _, _, ok := algo.Path(42); ok {
+		t.Error("expected no path to an out-of-range vertex")
+	}
+}
+
+func TestBMSSP_PathWithTies(t *testing.T) {
+	// Diamond with two equal-length routes to 3; either is fine, but it must be consistent.
+	g := &common.Graph[float64]{N: 5, Adj: make(map[int][]common.Edge[float64])}
+	add := func(u, v int, w float64) {
+		g.Adj[u] = append(g.Adj[u], common.Edge[float64]{U: u, V: v, Weight: w})
+		g.Adj[v] = append(g.Adj[v], common.Edge[float64]{U: v, V: u, Weight: w})
+	}
+	add(0, 1, 1)
+	add(0, 2, 1)
+	add(1, 3, 1)
+	add(2, 3, 1)
+	add(3, 4, 2)
+
+	algo := NewBMSSPAlgorithm(g, 3, 100.0, []int{0})
+	dist, err := algo.Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	for v := 0; v < g.N; v++ {
+		vertices, edges, ok := algo.Path(v)
+		if !ok || vertices[0] != 0 || vertices[len(vertices)-1] != v {
+			t.Fatalf("v%d: expected a path from the source, got %v (ok=%v)", v, vertices, ok)
+		}
+		if resVertices, _, _ := dist.Path(v); !slices.Equal(resVertices, vertices) {
+			t.Fatalf("v%d: Result.Path gave %v, BMSSPAlgorithm.Path gave %v", v, resVertices, vertices)
+		}
+		total := 0.0
+		for _, e := range edges {
+			total += e.Weight
+		}
+		if math.Abs(total-dist.Dist(v)) > 1e-9 {
+			t.Errorf("v%d: path weight %f does not match dist %f", v, total, dist.Dist(v))
+		}
+	}
+}
+
+func TestSolveSSSP_MatchesDijkstra(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		g := createRandomGraph(200, 600, seed)
+		res, err := SolveSSSP(g, []int{0})
+		if err != nil {
+			t.Fatalf("seed %d: SolveSSSP() returned an error: %v", seed, err)
+		}
+		if !math.IsInf(res.B, 1) {
+			t.Fatalf("seed %d: expected B=+Inf, got %f", seed, res.B)
+		}
+		if res.L != topLevelDepth(g.N, res.T) {
+			t.Fatalf("seed %d: unexpected recursion depth %d", seed, res.L)
+		}
+		if res.Repaired != 0 {
+			t.Fatalf("seed %d: the recursion left %d vertices to the verification pass", seed, res.Repaired)
+		}
+
+		want, _ := dijkstra.NewDijkstraAlgorithm(g, []int{0}, nil).Solve()
+		for v := 0; v < g.N; v++ {
+			if res.Dist(v) != want.Dist(v) {
+				t.Fatalf("seed %d: vertex %d: expected dist=%f, got %f", seed, v, want.Dist(v), res.Dist(v))
+			}
+		}
+	}
+
+	// Zero-weight edges produce ties and cycles that the recursion has to settle too.
+	for seed := int64(1); seed <= 20; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		b := common.NewGraphBuilder[int64](true).WithVertices(300)
+		for i := 0; i < 900; i++ {
+			b.AddEdge(r.Intn(300), r.Intn(300), int64(r.Intn(3)))
+		}
+		g := mustBuild(b)
+		res, err := SolveSSSP(g, []int{0, 5})
+		if err != nil {
+			t.Fatalf("seed %d: SolveSSSP() returned an error: %v", seed, err)
+		}
+		if res.Repaired != 0 {
+			t.Fatalf("seed %d: the recursion left %d vertices to the verification pass", seed, res.Repaired)
+		}
+		want, _ := dijkstra.NewDijkstraAlgorithm(g, []int{0, 5}, nil).Solve()
+		if !slices.Equal(res.Distances(), want.Distances()) {
+			t.Fatalf("seed %d: expected distances %v, got %v", seed, want.Distances(), res.Distances())
+		}
+	}
+}
+
+func TestSolveSSSP_Paths(t *testing.T) {
+	g := createLinearGraph(50)
+	res, err := SolveSSSP(g, []int{0})
+	if err != nil {
+		t.Fatalf("SolveSSSP() returned an error: %v", err)
+	}
+	vertices, _, ok := res.Path(49)
+	if !ok || len(vertices) != 50 {
+		t.Fatalf("expected a 50-vertex path, got %v (ok=%v)", vertices, ok)
+	}
+}
+
+func TestReduceDegree_BoundsDegree(t *testing.T) {
+	g := createRandomGraph(100, 1500, 7)
+	r := ReduceDegree(g, DefaultMaxDegree)
+
+	inDeg := make(map[int]int)
+	for u := 0; u < r.Graph.N; u++ {
+		if len(r.Graph.Adj[u]) > DefaultMaxDegree {
+			t.Fatalf("vertex %d has out-degree %d", u, len(r.Graph.Adj[u]))
+		}
+		for _, e := range r.Graph.Adj[u] {
+			inDeg[e.V]++
+		}
+	}
+	for v, d := range inDeg {
+		if d > DefaultMaxDegree {
+			t.Fatalf("vertex %d has in-degree %d", v, d)
+		}
+	}
+	for v := 0; v < g.N; v++ {
+		if r.OriginalVertex(v) != v {
+			t.Fatalf("original vertex %d was renumbered to %d", v, r.OriginalVertex(v))
+		}
+	}
+}
+
+func TestDegreeReducedBMSSP_MatchesDijkstra(t *testing.T) {
+	for seed := int64(1); seed <= 10; seed++ {
+		g := createRandomGraph(100, 1000, seed)
+		algo := NewDegreeReducedBMSSP(g, 3, 1000.0, []int{0})
+		dist, err := algo.Solve()
+		if err != nil {
+			t.Fatalf("seed %d: Solve() returned an error: %v", seed, err)
+		}
+		if dist.Len() != g.N {
+			t.Fatalf("seed %d: expected %d distances, got %d", seed, g.N, dist.Len())
+		}
+
+		want, _ := dijkstra.NewDijkstraAlgorithm(g, []int{0}, nil).Solve()
+		for v := 0; v < g.N; v++ {
+			if math.Abs(dist.Dist(v)-want.Dist(v)) > 1e-9 && !(math.IsInf(dist.Dist(v), 1) && math.IsInf(want.Dist(v), 1)) {
+				t.Fatalf("seed %d: vertex %d: expected dist=%f, got %f", seed, v, want.Dist(v), dist.Dist(v))
+			}
+			vertices, edges, ok := algo.Path(v)
+			if math.IsInf(want.Dist(v), 1) {
+				continue
+			}
+			if e, has := dist.Pred(v); v != 0 && (!has || e.V != v || math.Abs(dist.Dist(e.U)+e.Weight-dist.Dist(v)) > 1e-9) {
+				t.Fatalf("seed %d: v%d: bad predecessor %v (ok=%v)", seed, v, e, has)
+			}
+			if !ok || vertices[0] != 0 || vertices[len(vertices)-1] != v || len(edges) != len(vertices)-1 {
+				t.Fatalf("seed %d: v%d: bad path %v (ok=%v)", seed, v, vertices, ok)
+			}
+			total := 0.0
+			for i, e := range edges {
+				if e.U != vertices[i] || e.V != vertices[i+1] {
+					t.Fatalf("seed %d: v%d: edge %d (%d->%d) does not match %v", seed, v, i, e.U, e.V, vertices)
+				}
+				total += e.Weight
+			}
+			if math.Abs(total-want.Dist(v)) > 1e-9 {
+				t.Fatalf("seed %d: v%d: path weight %f does not match dist %f", seed, v, total, want.Dist(v))
+			}
+		}
+	}
+}
+
+func TestDegreeReducedBMSSP_NilGraph(t *testing.T) {
+	algo := NewDegreeReducedBMSSP[float64](nil, 2, 100, []int{0})
+	if _, err := algo.Solve(); !errors.Is(err, common.ErrInvalidParameter) {
+		t.Fatalf("expected %v, got %v", common.ErrInvalidParameter, err)
+	}
+	if _, _, ok := algo.Path(0); ok {
+		t.Error("expected no path before a successful Solve")
+	}
+}
+
+func TestBMSSP_Validation(t *testing.T) {
+	negative := createLinearGraph(4)
+	negative.Adj[2] = append(negative.Adj[2], common.Edge[float64]{U: 2, V: 3, Weight: -1})
+
+	nan := createLinearGraph(4)
+	nan.Adj[1][0].Weight = math.NaN()
+
+	misfiled := createLinearGraph(4)
+	misfiled.Adj[0] = append(misfiled.Adj[0], common.Edge[float64]{U: 1, V: 2, Weight: 1})
+
+	missing := createLinearGraph(4)
+	missing.Edges = append(missing.Edges, common.Edge[float64]{U: 0, V: 3, Weight: 2})
+
+	tests := []struct {
+		name string
+		algo *BMSSPAlgorithm[float64]
+		want error
+	}{
+		{"negative weight", NewBMSSPAlgorithm(negative, 2, 100, []int{0}), common.ErrNegativeWeight},
+		{"NaN weight", NewBMSSPAlgorithm(nan, 2, 100, []int{0}), common.ErrInvalidWeight},
+		{"misfiled adjacency", NewBMSSPAlgorithm(misfiled, 2, 100, []int{0}), common.ErrInconsistentGraph},
+		{"edge missing from Adj", NewBMSSPAlgorithm(missing, 2, 100, []int{0}), common.ErrInconsistentGraph},
+		{"source out of range", NewBMSSPAlgorithm(createLinearGraph(4), 2, 100, []int{4}), common.ErrSourceOutOfRange},
+		{"no sources", NewBMSSPAlgorithm(createLinearGraph(4), 2, 100, nil), common.ErrNoSources},
+		{"negative l", NewBMSSPAlgorithm(createLinearGraph(4), -1, 100, []int{0}), common.ErrInvalidParameter},
+		{"NaN B", NewBMSSPAlgorithm(createLinearGraph(4), 2, math.NaN(), []int{0}), common.ErrInvalidParameter},
+		{"threshold overflow", NewBMSSPAlgorithm(createLinearGraph(4), 64, 100, []int{0}), common.ErrInvalidParameter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.algo.Solve(); !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	var edgeErr *common.EdgeError[float64]
+	_, err := NewBMSSPAlgorithm(negative, 2, 100, []int{0}).Solve()
+	if !errors.As(err, &edgeErr) || edgeErr.Edge.Weight != -1 {
+		t.Errorf("expected an EdgeError carrying the negative edge, got %v", err)
+	}
+	var vertexErr *common.VertexError
+	_, err = NewBMSSPAlgorithm(createLinearGraph(4), 2, 100, []int{0, 7}).Solve()
+	if !errors.As(err, &vertexErr) || vertexErr.Vertex != 7 {
+		t.Errorf("expected a VertexError carrying source 7, got %v", err)
+	}
+
+	// Of several invalid edges, the one of the lowest vertex is reported every time.
+	several := createLinearGraph(50)
+	for u := 10; u < 40; u += 3 {
+		several.Adj[u][0].Weight = -1
+	}
+	for range 20 {
+		_, err := NewBMSSPAlgorithm(several, 2, 100, []int{0}).Solve()
+		if !errors.As(err, &edgeErr) || edgeErr.Edge.U != 10 {
+			t.Fatalf("expected the EdgeError of vertex 10, got %v", err)
+		}
+	}
+
+	// Edges may list the edges of Adj in any order.
+	shuffled := createLinearGraph(20)
+	shuffled.Edges = slices.Clone(shuffled.Edges)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled.Edges), func(i, j int) {
+		shuffled.Edges[i], shuffled.Edges[j] = shuffled.Edges[j], shuffled.Edges[i]
+	})
+	if _, err := NewBMSSPAlgorithm(shuffled, 2, 100, []int{0}).Solve(); err != nil {
+		t.Errorf("Solve() returned an error for a reordered edge list: %v", err)
+	}
+}
+
+func TestBMSSP_RepeatedSolveReusesConversion(t *testing.T) {
+	g := createLinearGraph(20)
+	algo := NewBMSSPAlgorithm(g, 3, 1000.0, []int{0})
+	first, err := algo.Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+
+	// The graph was converted by the first Solve, so later changes are not seen.
+	g.Adj[3][0].Weight = -1
+	second, err := algo.Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error on the second run: %v", err)
+	}
+	if !slices.Equal(first.Distances(), second.Distances()) {
+		t.Errorf("expected the same distances from both runs, got %v and %v", first.Distances(), second.Distances())
+	}
+}
+
+func TestBMSSP_SolveContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	g := createLinearGraph(20)
+	dist, err := NewBMSSPAlgorithm(g, 3, 1000.0, []int{0}).SolveContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if dist.Dist(0) != 0 {
+		t.Errorf("expected the source to stay settled, got %f", dist.Dist(0))
+	}
+	for v := 1; v < g.N; v++ {
+		if !math.IsInf(dist.Dist(v), 1) {
+			t.Errorf("vertex %d: expected +Inf after immediate cancellation, got %f", v, dist.Dist(v))
+		}
+	}
+}
+
+func TestBMSSP_SolveContext_PartialDistancesAreExact(t *testing.T) {
+	for seed := int64(1); seed <= 10; seed++ {
+		g := createRandomGraph(300, 900, seed)
+		want, _ := dijkstra.NewDijkstraAlgorithm(g, []int{0}, nil).Solve()
+
+		ctx := &countdownContext{Context: context.Background(), remaining: 50}
+		dist, err := NewBMSSPAlgorithm(g, 3, 1000.0, []int{0}).SolveContext(ctx)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("seed %d: expected context.Canceled, got %v", seed, err)
+		}
+		for v, d := range dist.All() {
+			if !math.IsInf(d, 1) && d != want.Dist(v) {
+				t.Fatalf("seed %d: vertex %d: partial dist %f is not the true dist %f", seed, v, d, want.Dist(v))
+			}
+		}
+	}
+}
+
+func TestSolveSSSPContext_DeadlineExceeded(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	res, err := SolveSSSPContext(ctx, createLinearGraph(50), []int{0})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if res == nil || res.Dist(0) != 0 || !math.IsInf(res.Dist(49), 1) {
+		t.Fatalf("expected a partial result, got %+v", res)
+	}
+}
+
+func TestBMSSP_Stats(t *testing.T) {
+	g := createRandomGraph(300, 900, 3)
+	if NewBMSSPAlgorithm(g, 2, 1000.0, []int{0}).Stats() != nil {
+		t.Fatal("expected nil Stats without WithStats")
+	}
+
+	algo := NewBMSSPAlgorithm(g, 2, 1000.0, []int{0}).WithStats()
+	if _, err := algo.Solve(); err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	stats := algo.Stats()
+	if len(stats.RecursionCalls) != 3 || stats.RecursionCalls[2] != 1 {
+		t.Fatalf("expected one top-level call over 3 levels, got %v", stats.RecursionCalls)
+	}
+	if stats.BaseCases < stats.RecursionCalls[0] {
+		t.Errorf("expected at least one base case per level-0 call, got %d for %d calls", stats.BaseCases, stats.RecursionCalls[0])
+	}
+	// Every pull starts exactly one call one level down.
+	if stats.Pulls != stats.RecursionCalls[0]+stats.RecursionCalls[1] {
+		t.Errorf("expected one recursive call per pull, got %d pulls and calls %v", stats.Pulls, stats.RecursionCalls)
+	}
+	if stats.Relaxations == 0 || stats.Inserts == 0 || stats.Pivots > stats.PivotSources {
+		t.Errorf("unexpected counters: %v", stats)
+	}
+}
+
+func TestWorkspace_MatchesSolveSSSP(t *testing.T) {
+	g := createRandomGraph(300, 900, 5)
+	w, err := NewWorkspace(g)
+	if err != nil {
+		t.Fatalf("NewWorkspace() returned an error: %v", err)
+	}
+
+	for _, sources := range [][]int{{0}, {7, 150}, {299}, {0}} {
+		if err := w.Query(sources, math.Inf(1)); err != nil {
+			t.Fatalf("sources %v: Query() returned an error: %v", sources, err)
+		}
+		if w.Repaired() != 0 {
+			t.Fatalf("sources %v: the recursion left %d vertices to the verification pass", sources, w.Repaired())
+		}
+		want, _ := dijkstra.NewDijkstraAlgorithm(g, sources, nil).Solve()
+		for v := 0; v < g.N; v++ {
+			if w.Dist(v) != want.Dist(v) {
+				t.Fatalf("sources %v: vertex %d: expected dist=%f, got %f", sources, v, want.Dist(v), w.Dist(v))
+			}
+		}
+	}
+
+	// A bounded query must not leak distances from the previous, unbounded one.
+	if err := w.Query([]int{0}, 3); err != nil {
+		t.Fatalf("Query() returned an error: %v", err)
+	}
+	if w.Repaired() != 0 {
+		t.Fatalf("the recursion left %d vertices to the verification pass", w.Repaired())
+	}
+	want, _ := dijkstra.NewDijkstraAlgorithm(g, []int{0}, nil).Solve()
+	for v := 0; v < g.N; v++ {
+		if want.Dist(v) < 3 && w.Dist(v) != want.Dist(v) {
+			t.Fatalf("vertex %d: expected dist=%f below the bound, got %f", v, want.Dist(v), w.Dist(v))
+		}
+		if want.Dist(v) >= 3 && !math.IsInf(w.Dist(v), 1) {
+			t.Fatalf("vertex %d: expected +Inf beyond the bound, got %f", v, w.Dist(v))
+		}
+	}
+
+	if err := w.Query([]int{300}, 10); !errors.Is(err, common.ErrSourceOutOfRange) {
+		t.Errorf("expected ErrSourceOutOfRange, got %v", err)
+	}
+}
+
+func TestWorkspace_QueryDoesNotAllocate(t *testing.T) {
+	g := createRandomGraph(500, 1500, 9)
+	w, err := NewWorkspace(g)
+	if err != nil {
+		t.Fatalf("NewWorkspace() returned an error: %v", err)
+	}
+	sources := [][]int{{0}, {42}, {100, 200}}
+	for _, s := range sources {
+		_ = w.Query(s, math.Inf(1)) // Grow the buffers to their working size.
+	}
+
+	i := 0
+	allocs := testing.AllocsPerRun(50, func() {
+		if err := w.Query(sources[i%len(sources)], math.Inf(1)); err != nil {
+			t.Fatalf("Query() returned an error: %v", err)
+		}
+		i++
+	})
+	if allocs != 0 {
+		t.Errorf("expected Query to be allocation-free, got %v allocs per run", allocs)
+	}
+}
+
+func TestBMSSP_TargetsStopEarly(t *testing.T) {
+	g := createLinearGraph(50)
+	dist, err := NewBMSSPAlgorithm(g, 3, 1000.0, []int{0}).WithTargets(3).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	if dist.Dist(3) != 3 {
+		t.Errorf("vertex 3: expected dist=3, got %f", dist.Dist(3))
+	}
+	if !math.IsInf(dist.Dist(49), 1) {
+		t.Errorf("expected the search to stop before vertex 49, got %f", dist.Dist(49))
+	}
+
+	dist, err = NewBMSSPAlgorithm(g, 3, 1000.0, []int{0}).WithTargets(0).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	if dist.Dist(0) != 0 || dist.Reachable(1) {
+		t.Errorf("expected only the source target to be settled, got %v", dist.Map())
+	}
+}
+
+func TestWorkspace_Targets(t *testing.T) {
+	g := createLinearGraph(50)
+	w, err := NewWorkspace(g)
+	if err != nil {
+		t.Fatalf("NewWorkspace() returned an error: %v", err)
+	}
+
+	if err := w.Query([]int{0}, math.Inf(1), 3); err != nil {
+		t.Fatalf("Query() returned an error: %v", err)
+	}
+	if w.Dist(3) != 3 {
+		t.Errorf("vertex 3: expected dist=3, got %f", w.Dist(3))
+	}
+	if !math.IsInf(w.Dist(49), 1) {
+		t.Errorf("expected the query to stop before vertex 49, got %f", w.Dist(49))
+	}
+
+	// A query without targets must not inherit the previous ones.
+	if err := w.Query([]int{0}, math.Inf(1)); err != nil {
+		t.Fatalf("Query() returned an error: %v", err)
+	}
+	if w.Dist(49) != 49 {
+		t.Errorf("vertex 49: expected dist=49, got %f", w.Dist(49))
+	}
+
+	if err := w.Query([]int{0}, math.Inf(1), 50); !errors.Is(err, common.ErrTargetOutOfRange) {
+		t.Errorf("expected ErrTargetOutOfRange, got %v", err)
+	}
+}
+
+func TestBMSSP_TargetsMatchDijkstra(t *testing.T) {
+	targets := []int{7, 150, 299, 150}
+	for seed := int64(1); seed <= 10; seed++ {
+		g := createRandomGraph(300, 900, seed)
+		want, _ := dijkstra.NewDijkstraAlgorithm(g, []int{0}, nil).Solve()
+
+		dist, err := NewBMSSPAlgorithm(g, 3, 1000.0, []int{0}).WithTargets(targets...).Solve()
+		if err != nil {
+			t.Fatalf("seed %d: Solve() returned an error: %v", seed, err)
+		}
+		for _, v := range targets {
+			if dist.Dist(v) != want.Dist(v) {
+				t.Errorf("seed %d: target %d: expected dist=%f, got %f", seed, v, want.Dist(v), dist.Dist(v))
+			}
+		}
+		for v, d := range dist.Reached() {
+			if d != want.Dist(v) {
+				t.Fatalf("seed %d: vertex %d: reported dist %f is not the true dist %f", seed, v, d, want.Dist(v))
+			}
+		}
+	}
+
+	_, err := NewBMSSPAlgorithm(createLinearGraph(4), 2, 100, []int{0}).WithTargets(9).Solve()
+	if !errors.Is(err, common.ErrTargetOutOfRange) {
+		t.Errorf("expected ErrTargetOutOfRange, got %v", err)
+	}
+}
+
+func TestBMSSP_CSRMatchesGraph(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		g := createRandomGraph(300, 900, seed)
+		csr, err := common.NewCSRGraph(g)
+		if err != nil {
+			t.Fatalf("NewCSRGraph() returned an error: %v", err)
+		}
+		if csr.NumVertices() != g.N || csr.NumEdges() != len(g.Edges) {
+			t.Fatalf("seed %d: expected %d vertices and %d edges, got %d and %d",
+				seed, g.N, len(g.Edges), csr.NumVertices(), csr.NumEdges())
+		}
+
+		want, err := NewBMSSPAlgorithm(g, 3, 1000.0, []int{0}).Solve()
+		if err != nil {
+			t.Fatalf("Solve() returned an error: %v", err)
+		}
+		got, err := NewBMSSPAlgorithmCSR(csr, 3, 1000.0, []int{0}).Solve()
+		if err != nil {
+			t.Fatalf("Solve() returned an error: %v", err)
+		}
+		sssp, err := SolveSSSPCSR(csr, []int{0})
+		if err != nil {
+			t.Fatalf("SolveSSSPCSR() returned an error: %v", err)
+		}
+		for v := 0; v < g.N; v++ {
+			if got.Dist(v) != want.Dist(v) || sssp.Dist(v) != want.Dist(v) {
+				t.Fatalf("seed %d: vertex %d: expected dist=%f, got %f (BMSSP) and %f (SolveSSSP)",
+					seed, v, want.Dist(v), got.Dist(v), sssp.Dist(v))
+			}
+		}
+	}
+
+	if _, err := common.NewCSRGraph(&common.Graph[float64]{N: 2, Adj: map[int][]common.Edge[float64]{0: {{U: 0, V: 1, Weight: -1}}}}); !errors.Is(err, common.ErrNegativeWeight) {
+		t.Errorf("expected ErrNegativeWeight, got %v", err)
+	}
+}
+
+func TestSolveSSSPLabeled(t *testing.T) {
+	g, err := common.NewLabeledGraphBuilder[string, float64](false).
+		AddEdge("A", "B", 1).
+		AddEdge("B", "C", 2).
+		AddEdge("C", "D", 1).
+		AddEdge("A", "D", 5).
+		AddVertex("E").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() returned an error: %v", err)
+	}
+
+	res, err := SolveSSSPLabeled(g, []string{"A"})
+	if err != nil {
+		t.Fatalf("SolveSSSPLabeled() returned an error: %v", err)
+	}
+	want := map[string]float64{"A": 0, "B": 1, "C": 3, "D": 4, "E": math.Inf(1)}
+	if got := res.LabelMap(); !maps.Equal(got, want) {
+		t.Errorf("expected distances %v, got %v", want, got)
+	}
+	if path, ok := res.PathTo("D"); !ok || !slices.Equal(path, []string{"A", "B", "C", "D"}) {
+		t.Errorf("expected path A-B-C-D, got %v (ok=%v)", path, ok)
+	}
+
+	if _, err := SolveSSSPLabeled(g, []string{"A"}, "Z"); !errors.Is(err, common.ErrUnknownLabel) {
+		t.Errorf("expected ErrUnknownLabel, got %v", err)
+	}
+}
+
+func TestBMSSP_IntegerWeights(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		testIntegerWeights(t, createRandomIntGraph[int64](300, 900, seed))
+		testIntegerWeights(t, createRandomIntGraph[uint32](300, 900, seed))
+		testIntegerWeights(t, createRandomIntGraph[int32](300, 900, seed))
+	}
+}
+
+func testIntegerWeights[W common.Weight](t *testing.T, g *common.Graph[W]) {
+	t.Helper()
+	want, err := dijkstra.NewDijkstraAlgorithm(g, []int{0}, nil).Solve()
+	if err != nil {
+		t.Fatalf("Dijkstra Solve() returned an error: %v", err)
+	}
+	for _, kind := range []DataStructureKind{HeapD, BlockD} {
+		got, err := NewBMSSPAlgorithm(g, 3, common.Inf[W](), []int{0}).WithDataStructure(kind).Solve()
+		if err != nil {
+			t.Fatalf("Solve() returned an error: %v", err)
+		}
+		for v := 0; v < g.N; v++ {
+			if got.Dist(v) != want.Dist(v) {
+				t.Fatalf("%T weights, D=%v: vertex %d: expected dist=%v, got %v", W(0), kind, v, want.Dist(v), got.Dist(v))
+			}
+		}
+	}
+}
+
+func TestSolveSSSPReverse(t *testing.T) {
+	const depot = 0
+	for seed := int64(1); seed <= 5; seed++ {
+		g := createRandomGraph(200, 800, seed)
+		res, err := SolveSSSPReverse(g, []int{depot})
+		if err != nil {
+			t.Fatalf("SolveSSSPReverse() returned an error: %v", err)
+		}
+		if !res.Reverse() {
+			t.Fatal("expected a reverse result")
+		}
+		for _, v := range []int{1, 17, 99, 150, 199} {
+			forward, _ := dijkstra.NewDijkstraAlgorithm(g, []int{v}, nil).Solve()
+			if res.Dist(v) != forward.Dist(depot) {
+				t.Fatalf("seed %d: vertex %d: expected dist to depot=%f, got %f", seed, v, forward.Dist(depot), res.Dist(v))
+			}
+			if !res.Reachable(v) {
+				continue
+			}
+			vertices, edges, ok := res.Path(v)
+			if !ok || vertices[0] != v || vertices[len(vertices)-1] != depot {
+				t.Fatalf("seed %d: expected a path from %d to the depot, got %v (ok=%v)", seed, v, vertices, ok)
+			}
+			var sum float64
+			for i, e := range edges {
+				if e.U != vertices[i] || e.V != vertices[i+1] || !slices.Contains(g.Adj[e.U], e) {
+					t.Fatalf("seed %d: path edge %v is not an edge of g", seed, e)
+				}
+				sum += e.Weight
+			}
+			if sum != res.Dist(v) {
+				t.Fatalf("seed %d: path of %d has length %f, expected %f", seed, v, sum, res.Dist(v))
+			}
+		}
+
+		algo := NewBMSSPAlgorithm(g, 3, 1000.0, []int{depot}).WithReverse()
+		if _, err := algo.Solve(); err != nil {
+			t.Fatalf("Solve() returned an error: %v", err)
+		}
+		if vertices, _, ok := algo.Path(1); ok && vertices[len(vertices)-1] != depot {
+			t.Errorf("seed %d: expected BMSSPAlgorithm.Path to end at the depot, got %v", seed, vertices)
+		}
+	}
+}
+
 // --- Helper Functions ---
 
-func createLinearGraph(n int) *common.Graph {
-	g := &common.Graph{
-		N:   n,
-		Adj: make(map[int][]common.Edge),
+// countdownContext reports cancellation once Err has been called remaining times.
+type countdownContext struct {
+	context.Context
+	remaining int
+}
+
+func (c *countdownContext) Err() error {
+	if c.remaining <= 0 {
+		return context.Canceled
 	}
-	edges := make([]common.Edge, 0, n-1)
+	c.remaining--
+	return nil
+}
+
+func createLinearGraph(n int) *common.Graph[float64] {
+	b := common.NewGraphBuilder[float64](false).WithVertices(n)
 	for i := 0; i < n-1; i++ {
-		edges = append(edges, common.Edge{U: i, V: i + 1, Weight: 1.0})
+		b.AddEdge(i, i+1, 1.0)
 	}
-	for _, e := range edges {
-		g.Adj[e.U] = append(g.Adj[e.U], e)
-		g.Adj[e.V] = append(g.Adj[e.V], common.Edge{U: e.V, V: e.U, Weight: e.Weight})
-	}
-	g.Edges = edges
-	return g
+	return mustBuild(b)
 }
 
-func createCycleGraph() *common.Graph {
-	g := &common.Graph{
-		N:   4,
-		Adj: make(map[int][]common.Edge),
+func createCycleGraph() *common.Graph[float64] {
+	return mustBuild(common.NewGraphBuilder[float64](false).
+		AddEdge(0, 1, 1.0).
+		AddEdge(1, 2, 2.0).
+		AddEdge(2, 3, 1.0).
+		AddEdge(3, 0, 5.0))
+}
+
+func createRandomGraph(n, m int, seed int64) *common.Graph[float64] {
+	r := rand.New(rand.NewSource(seed))
+	b := common.NewGraphBuilder[float64](true).WithVertices(n)
+	for i := 0; i < m; i++ {
+		b.AddEdge(r.Intn(n), r.Intn(n), float64(r.Intn(10)+1))
 	}
-	edges := []common.Edge{
-		{0, 1, 1.0},
-		{1, 2, 2.0},
-		{2, 3, 1.0},
-		{3, 0, 5.0},
+	return mustBuild(b)
+}
+
+func createRandomIntGraph[W common.Weight](n, m int, seed int64) *common.Graph[W] {
+	r := rand.New(rand.NewSource(seed))
+	b := common.NewGraphBuilder[W](true).WithVertices(n)
+	for i := 0; i < m; i++ {
+		b.AddEdge(r.Intn(n), r.Intn(n), W(r.Intn(10)+1))
 	}
-	for _, e := range edges {
-		g.Adj[e.U] = append(g.Adj[e.U], e)
-		g.Adj[e.V] = append(g.Adj[e.V], common.Edge{U: e.V, V: e.U, Weight: e.Weight})
+	return mustBuild(b)
+}
+
+// mustBuild builds a graph from a helper's fixed, valid edge list.
+func mustBuild[W common.Weight](b *common.GraphBuilder[W]) *common.Graph[W] {
+	g, err := b.Build()
+	if err != nil {
+		panic(err)
 	}
-	g.Edges = edges
 	return g
 }
@@ -5,25 +5,61 @@ import (
 	"playground/common"
 )
 
+// PullQueue is the method set of the data structure D from Lemma 3.3 used by the
+// BMSSP pull loop.
+type PullQueue[W common.Weight] interface {
+	Initialize(M int, B W)
+	Insert(v int, dist W)
+	Pull() (W, []int)
+	BatchPrepend(entries []common.DistEntry[W])
+	IsEmpty() bool
+}
+
+// DataStructureKind selects the PullQueue implementation used by BMSSPAlgorithm.
+type DataStructureKind int
+
+const (
+	// HeapD is the binary-heap DataStructureD.
+	HeapD DataStructureKind = iota
+	// BlockD is the block-based BlockDataStructureD with the bounds from Lemma 3.3.
+	BlockD
+)
+
+func newPullQueue[W common.Weight](kind DataStructureKind) PullQueue[W] {
+	if kind == BlockD {
+		return &BlockDataStructureD[W]{}
+	}
+	return &DataStructureD[W]{}
+}
+
 // DataStructureD is a specialised priority queue for the BMSSP algorithm.
-type DataStructureD struct {
-	pq     common.PriorityQueue
-	inHeap map[int]*common.DistEntry
-	M      int
-	B      float64
+// Re-initializing it reuses its buffers, so a DataStructureD that is initialized
+// many times stops allocating once it has grown to its working size.
+type DataStructureD[W common.Weight] struct {
+	pq      common.PriorityQueue[W]
+	inHeap  map[int]*common.DistEntry[W]
+	entries common.EntryPool[W]
+	pulled  []int
+	M       int
+	B       W
 }
 
 // Initialize sets up the DataStructureD with a batch size M and upper bound B.
-func (d *DataStructureD) Initialize(M int, B float64) {
+func (d *DataStructureD[W]) Initialize(M int, B W) {
 	d.M = M
 	d.B = B
-	d.pq = make(common.PriorityQueue, 0)
-	d.inHeap = make(map[int]*common.DistEntry)
-	heap.Init(&d.pq)
+	if d.inHeap == nil {
+		d.inHeap = make(map[int]*common.DistEntry[W])
+	}
+	for _, e := range d.pq {
+		delete(d.inHeap, e.Vertex)
+	}
+	d.pq = d.pq[:0]
+	d.entries.Reset()
 }
 
 // Insert adds a vertex with its distance, updating the value if a shorter path is found.
-func (d *DataStructureD) Insert(v int, dist float64) {
+func (d *DataStructureD[W]) Insert(v int, dist W) {
 	if dist >= d.B {
 		return
 	}
@@ -34,23 +70,24 @@ func (d *DataStructureD) Insert(v int, dist float64) {
 		}
 		return
 	}
-	item := &common.DistEntry{Vertex: v, Dist: dist}
+	item := d.entries.Get(v, dist)
 	heap.Push(&d.pq, item)
 	d.inHeap[v] = item
 }
 
 // Pull pops up to M items, but NEVER splits ties: if the next key equals the last
 // popped key, we keep popping to drain the entire tie group. Returns (Bi, S')
-// where Bi is the next strictly larger key (or B if none).
-func (d *DataStructureD) Pull() (float64, []int) {
+// where Bi is the next strictly larger key (or B if none). S' is only valid until
+// the next call to Pull or Initialize.
+func (d *DataStructureD[W]) Pull() (W, []int) {
 	if d.pq.Len() == 0 {
 		return d.B, nil
 	}
 
-	Sprime := make([]int, 0, d.M)
+	Sprime := d.pulled[:0]
 
 	// Pop the first item to determine the tie key.
-	first := heap.Pop(&d.pq).(*common.DistEntry)
+	first := heap.Pop(&d.pq).(*common.DistEntry[W])
 	delete(d.inHeap, first.Vertex)
 	Sprime = append(Sprime, first.Vertex)
 	popped := 1
@@ -61,12 +98,15 @@ func (d *DataStructureD) Pull() (float64, []int) {
 		if popped >= d.M && next.Dist > tieKey {
 			break
 		}
-		entry := heap.Pop(&d.pq).(*common.DistEntry)
+		entry := heap.Pop(&d.pq).(*common.DistEntry[W])
 		delete(d.inHeap, entry.Vertex)
 		Sprime = append(Sprime, entry.Vertex)
 		popped++
+		tieKey = entry.Dist
 	}
 
+	d.pulled = Sprime
+
 	// Bi = next strictly larger key, capped at B; if none, Bi = B.
 	if d.pq.Len() == 0 {
 		return d.B, Sprime
@@ -79,22 +119,13 @@ func (d *DataStructureD) Pull() (float64, []int) {
 }
 
 // BatchPrepend inserts a list of entries, keeping the smallest distance per vertex.
-func (d *DataStructureD) BatchPrepend(entries []common.DistEntry) {
-	if len(entries) == 0 {
-		return
-	}
-	best := make(map[int]float64, len(entries))
+func (d *DataStructureD[W]) BatchPrepend(entries []common.DistEntry[W]) {
 	for _, e := range entries {
-		if cur, ok := best[e.Vertex]; !ok || e.Dist < cur {
-			best[e.Vertex] = e.Dist
-		}
-	}
-	for v, dist := range best {
-		d.Insert(v, dist)
+		d.Insert(e.Vertex, e.Dist)
 	}
 }
 
 // IsEmpty checks if the data structure is empty.
-func (d *DataStructureD) IsEmpty() bool {
+func (d *DataStructureD[W]) IsEmpty() bool {
 	return d.pq.Len() == 0
 }
@@ -0,0 +1,230 @@
+package bmssp
+
+import (
+	"context"
+	"fmt"
+	"playground/common"
+)
+
+// DefaultMaxDegree is the in/out degree bound assumed by the BMSSP runtime analysis.
+const DefaultMaxDegree = 2
+
+// DegreeReducedGraph is a constant-degree copy of a graph. Every vertex whose in- or
+// out-degree exceeds the bound is replaced by a zero-weight cycle of gadget vertices,
+// one per incident edge. The first gadget keeps the original id and the others are
+// numbered from Original.N upwards, so original ids stay valid in Graph.
+type DegreeReducedGraph[W common.Weight] struct {
+	Graph    *common.Graph[W]
+	Original *common.Graph[W]
+	owner    []int // Original vertex of every vertex in Graph.
+}
+
+// ReduceDegree builds the constant-degree transformation of g, expanding every vertex
+// with in- or out-degree above maxDegree. Values of maxDegree below 2 are treated as 2,
+// since that is the degree of a gadget vertex.
+func ReduceDegree[W common.Weight](g *common.Graph[W], maxDegree int) *DegreeReducedGraph[W] {
+	if maxDegree < 2 {
+		maxDegree = 2
+	}
+
+	inDeg := make([]int, g.N)
+	for u := 0; u < g.N; u++ {
+		for _, e := range g.Adj[u] {
+			if e.V >= 0 && e.V < g.N {
+				inDeg[e.V]++
+			}
+		}
+	}
+
+	// slots[v] lists the gadget vertices of v in cycle order; nil if v is not expanded.
+	slots := make([][]int, g.N)
+	owner := make([]int, g.N)
+	for v := 0; v < g.N; v++ {
+		owner[v] = v
+	}
+	for v := 0; v < g.N; v++ {
+		deg := len(g.Adj[v]) + inDeg[v]
+		if len(g.Adj[v]) <= maxDegree && inDeg[v] <= maxDegree {
+			continue
+		}
+		slots[v] = make([]int, deg)
+		slots[v][0] = v
+		for i := 1; i < deg; i++ {
+			slots[v][i] = len(owner)
+			owner = append(owner, v)
+		}
+	}
+
+	h := &common.Graph[W]{N: len(owner), Adj: make(map[int][]common.Edge[W], len(owner)), Directed: true}
+	add := func(e common.Edge[W]) {
+		h.Adj[e.U] = append(h.Adj[e.U], e)
+		h.Edges = append(h.Edges, e)
+	}
+
+	for v := 0; v < g.N; v++ {
+		cycle := slots[v]
+		for i := range cycle {
+			add(common.Edge[W]{U: cycle[i], V: cycle[(i+1)%len(cycle)], Weight: 0})
+		}
+	}
+
+	// Out-edges of an expanded vertex take the first slots, in-edges the rest.
+	used := make([]int, g.N)
+	inUsed := make([]int, g.N)
+	for u := 0; u < g.N; u++ {
+		for _, e := range g.Adj[u] {
+			tail := u
+			if slots[u] != nil {
+				tail = slots[u][used[u]]
+				used[u]++
+			}
+			head, v := e.V, e.V
+			if v >= 0 && v < g.N && slots[v] != nil {
+				head = slots[v][len(g.Adj[v])+inUsed[v]]
+				inUsed[v]++
+			}
+			add(common.Edge[W]{U: tail, V: head, Weight: e.Weight})
+		}
+	}
+
+	return &DegreeReducedGraph[W]{Graph: h, Original: g, owner: owner}
+}
+
+// OriginalVertex returns the vertex of the original graph that x belongs to.
+func (r *DegreeReducedGraph[W]) OriginalVertex(x int) int {
+	if x < 0 || x >= len(r.owner) {
+		return x
+	}
+	return r.owner[x]
+}
+
+// Distances restricts a result over the transformed graph to the original vertices.
+// The predecessor of an original vertex is the edge that entered its gadget.
+func (r *DegreeReducedGraph[W]) Distances(res *common.Result[W]) *common.Result[W] {
+	dist := make([]W, r.Original.N)
+	var pred []common.Edge[W]
+	if res.HasPredecessors() {
+		pred = make([]common.Edge[W], r.Original.N)
+	}
+	for v := range dist {
+		dist[v] = res.Dist(v)
+		if pred == nil {
+			continue
+		}
+		pred[v] = common.Edge[W]{U: -1, V: v}
+		// Walk back around the gadget cycle; it has fewer than len(r.owner) edges.
+		x := v
+		for range len(r.owner) {
+			e, ok := res.Pred(x)
+			if !ok {
+				break
+			}
+			if u := r.OriginalVertex(e.U); u != v || e.Weight != 0 {
+				pred[v] = common.Edge[W]{U: u, V: v, Weight: e.Weight}
+				break
+			}
+			x = e.U
+		}
+	}
+	return common.NewResult(dist, pred)
+}
+
+// Path maps a path through the transformed graph back to original vertices and edges,
+// dropping the zero-weight cycle edges inside gadgets.
+func (r *DegreeReducedGraph[W]) Path(vertices []int, edges []common.Edge[W]) ([]int, []common.Edge[W]) {
+	outVertices := make([]int, 0, len(vertices))
+	for _, x := range vertices {
+		v := r.OriginalVertex(x)
+		if len(outVertices) == 0 || outVertices[len(outVertices)-1] != v {
+			outVertices = append(outVertices, v)
+		}
+	}
+
+	outEdges := make([]common.Edge[W], 0, len(edges))
+	for _, e := range edges {
+		u, v := r.OriginalVertex(e.U), r.OriginalVertex(e.V)
+		if u == v && e.Weight == 0 {
+			continue
+		}
+		outEdges = append(outEdges, common.Edge[W]{U: u, V: v, Weight: e.Weight})
+	}
+	return outVertices, outEdges
+}
+
+// DegreeReducedBMSSP runs BMSSPAlgorithm on the constant-degree transformation of a
+// graph and reports distances and paths in terms of the original vertex ids.
+type DegreeReducedBMSSP[W common.Weight] struct {
+	original *common.Graph[W]
+	reduced  *DegreeReducedGraph[W] // Built by the first Solve, once original is valid.
+	algo     *BMSSPAlgorithm[W]
+}
+
+// NewDegreeReducedBMSSP prepares a BMSSP run with the given l, B and sources over the
+// transformation of g with DefaultMaxDegree. The transformation is built by the first
+// Solve, after g has been validated.
+func NewDegreeReducedBMSSP[W common.Weight](g *common.Graph[W], l int, B W, S []int) *DegreeReducedBMSSP[W] {
+	return &DegreeReducedBMSSP[W]{
+		original: g,
+		algo:     NewBMSSPAlgorithm[W](nil, l, B, S),
+	}
+}
+
+// WithDataStructure selects the implementation of D used by the pull loop.
+func (a *DegreeReducedBMSSP[W]) WithDataStructure(kind DataStructureKind) *DegreeReducedBMSSP[W] {
+	a.algo.WithDataStructure(kind)
+	return a
+}
+
+// WithStats enables collection of Stats for the run on the transformed graph.
+func (a *DegreeReducedBMSSP[W]) WithStats() *DegreeReducedBMSSP[W] {
+	a.algo.WithStats()
+	return a
+}
+
+// Stats returns the counters of the last Solve, or nil if WithStats was not called.
+func (a *DegreeReducedBMSSP[W]) Stats() *Stats {
+	return a.algo.Stats()
+}
+
+// Reduced returns the transformed graph the algorithm runs on, or nil before the first Solve.
+func (a *DegreeReducedBMSSP[W]) Reduced() *DegreeReducedGraph[W] {
+	return a.reduced
+}
+
+// Solve runs BMSSP on the transformed graph and returns distances of the original vertices.
+// Input errors are reported against the original graph.
+func (a *DegreeReducedBMSSP[W]) Solve() (*common.Result[W], error) {
+	return a.SolveContext(context.Background())
+}
+
+// SolveContext is like Solve but stops once ctx is done, see BMSSPAlgorithm.SolveContext.
+func (a *DegreeReducedBMSSP[W]) SolveContext(ctx context.Context) (*common.Result[W], error) {
+	if a.reduced == nil {
+		if err := common.ValidateGraph(a.original); err != nil {
+			return nil, fmt.Errorf("bmssp: %w", err)
+		}
+		a.reduced = ReduceDegree(a.original, DefaultMaxDegree)
+		a.algo.source = a.reduced.Graph
+	}
+	if err := common.ValidateSources(a.original, a.algo.S); err != nil {
+		return nil, fmt.Errorf("bmssp: %w", err)
+	}
+	dist, err := a.algo.SolveContext(ctx)
+	if dist == nil {
+		return nil, err
+	}
+	return a.reduced.Distances(dist), err
+}
+
+// Path returns a shortest path to target in terms of the original graph, see BMSSPAlgorithm.Path.
+func (a *DegreeReducedBMSSP[W]) Path(target int) ([]int, []common.Edge[W], bool) {
+	if a.reduced == nil || target < 0 || target >= a.original.N {
+		return nil, nil, false
+	}
+	vertices, edges, ok := a.algo.Path(target)
+	if !ok {
+		return nil, nil, false
+	}
+	vertices, edges = a.reduced.Path(vertices, edges)
+	return vertices, edges, true
+}
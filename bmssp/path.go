@@ -0,0 +1,63 @@
+package bmssp
+
+import (
+	"playground/common"
+	"slices"
+)
+
+// ShortestPathTree returns the predecessor edge of every reached non-source vertex.
+// Predecessors are only replaced on strict improvements, so zero-weight edges
+// relaxed with <= can never introduce a cycle into the tree. After a reverse search
+// the tree holds the first edge of every vertex's path toward the destinations.
+func (a *BMSSPAlgorithm[W]) ShortestPathTree() map[int]common.Edge[W] {
+	tree := make(map[int]common.Edge[W])
+	for v := 0; v < a.n; v++ {
+		if a.hasPred.Has(v) {
+			tree[v] = a.treeEdge(v)
+		}
+	}
+	return tree
+}
+
+// treeEdge returns the predecessor edge of v, oriented as in the original graph.
+func (a *BMSSPAlgorithm[W]) treeEdge(v int) common.Edge[W] {
+	e := a.pred[v]
+	if a.reverse {
+		e.U, e.V = e.V, e.U
+	}
+	return e
+}
+
+// Path returns the vertices and edges of a shortest path from the nearest source
+// to target, or from target to the nearest destination after a reverse search. ok is
+// false if target is out of range or was not reached below B.
+func (a *BMSSPAlgorithm[W]) Path(target int) (vertices []int, edges []common.Edge[W], ok bool) {
+	if target < 0 || target >= a.n || a.distance(target) == a.inf {
+		return nil, nil, false
+	}
+
+	vertices = []int{target}
+	for v := target; ; {
+		if !a.hasPred.Has(v) {
+			break
+		}
+		e := a.treeEdge(v)
+		// A well-formed tree has at most N-1 edges on any root path.
+		if len(edges) >= a.n {
+			return nil, nil, false
+		}
+		edges = append(edges, e)
+		if a.reverse {
+			v = e.V
+		} else {
+			v = e.U
+		}
+		vertices = append(vertices, v)
+	}
+
+	if !a.reverse {
+		slices.Reverse(vertices)
+		slices.Reverse(edges)
+	}
+	return vertices, edges, true
+}
@@ -0,0 +1,173 @@
+package bmssp
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"playground/common"
+)
+
+// SSSPResult holds the distances computed by SolveSSSP and the parameters chosen for the top-level call.
+type SSSPResult[W common.Weight] struct {
+	*common.Result[W]
+
+	L int // Recursion depth, ceil(log2(n) / t).
+	K int // Pivot parameter k from kt().
+	T int // Level parameter t from kt().
+	B W   // Always common.Inf[W]() for the top-level call.
+
+	// Repaired counts vertices whose distance the final verification pass had to
+	// lower. The recursion settles every reachable vertex with these parameters, so a
+	// non-zero value points to a bug in it rather than to an expected fallback.
+	Repaired int
+}
+
+// SolveSSSP computes unbounded shortest paths from sources, following the paper's top-level call:
+// l = ceil(log2 n / t) and B = +Inf. With these parameters the k·2^{lt} threshold is at least n,
+// so the pull loop only stops once D is empty and the recursion alone settles every reachable
+// vertex. A final edge scan verifies this and reports any vertex it had to fix in Repaired.
+func SolveSSSP[W common.Weight](g *common.Graph[W], sources []int) (*SSSPResult[W], error) {
+	return SolveSSSPContext(context.Background(), g, sources)
+}
+
+// SolveSSSPContext is like SolveSSSP but stops once ctx is done. It then returns ctx.Err()
+// together with a result that only holds the distances settled so far.
+func SolveSSSPContext[W common.Weight](ctx context.Context, g *common.Graph[W], sources []int) (*SSSPResult[W], error) {
+	algo := NewBMSSPAlgorithm(g, 0, common.Inf[W](), sources)
+	if err := algo.validateGraph(); err != nil {
+		return nil, err
+	}
+	return algo.solveSSSP(ctx)
+}
+
+// SolveSSSPCSR is like SolveSSSP but runs on a CSRGraph.
+func SolveSSSPCSR[W common.Weight](g *common.CSRGraph[W], sources []int) (*SSSPResult[W], error) {
+	return SolveSSSPCSRContext(context.Background(), g, sources)
+}
+
+// SolveSSSPCSRContext is like SolveSSSPContext but runs on a CSRGraph.
+func SolveSSSPCSRContext[W common.Weight](ctx context.Context, g *common.CSRGraph[W], sources []int) (*SSSPResult[W], error) {
+	return NewBMSSPAlgorithmCSR(g, 0, common.Inf[W](), sources).solveSSSP(ctx)
+}
+
+// SolveSSSPReverse computes the distance from every vertex to the nearest of
+// destinations by running SolveSSSP on the transpose of g, see BMSSPAlgorithm.WithReverse.
+func SolveSSSPReverse[W common.Weight](g *common.Graph[W], destinations []int) (*SSSPResult[W], error) {
+	return SolveSSSPReverseContext(context.Background(), g, destinations)
+}
+
+// SolveSSSPReverseContext is like SolveSSSPReverse but stops once ctx is done.
+func SolveSSSPReverseContext[W common.Weight](ctx context.Context, g *common.Graph[W], destinations []int) (*SSSPResult[W], error) {
+	algo := NewBMSSPAlgorithm(g, 0, common.Inf[W](), destinations).WithReverse()
+	if err := algo.validateGraph(); err != nil {
+		return nil, err
+	}
+	return algo.solveSSSP(ctx)
+}
+
+// solveSSSP picks the top-level parameters and runs the recursion followed by the
+// verification pass on a validated graph. The pass is skipped once all targets are settled.
+func (a *BMSSPAlgorithm[W]) solveSSSP(ctx context.Context) (*SSSPResult[W], error) {
+	k, t := a.kt()
+	a.l = topLevelDepth(a.n, t)
+	if err := a.validateQuery(); err != nil {
+		return nil, err
+	}
+	err := a.run(ctx)
+	repaired := 0
+	if err == nil && !a.done {
+		repaired, err = a.settleRemaining(ctx)
+	}
+
+	return &SSSPResult[W]{
+		Result:   a.result(),
+		L:        a.l,
+		K:        k,
+		T:        t,
+		B:        a.B,
+		Repaired: repaired,
+	}, err
+}
+
+// SolveSSSPLabeled is like SolveSSSP on a labeled graph, taking sources and targets by
+// label. A non-empty target set stops the search early, see BMSSPAlgorithm.WithTargets.
+func SolveSSSPLabeled[K comparable, W common.Weight](g *common.LabeledGraph[K, W], sources []K, targets ...K) (*common.LabeledResult[K, W], error) {
+	src, err := g.Labels.IDs(sources)
+	if err != nil {
+		return nil, fmt.Errorf("bmssp: %w", err)
+	}
+	dst, err := g.Labels.IDs(targets)
+	if err != nil {
+		return nil, fmt.Errorf("bmssp: %w", err)
+	}
+	algo := NewBMSSPAlgorithm(g.Graph, 0, common.Inf[W](), src).WithTargets(dst...)
+	if err := algo.validateGraph(); err != nil {
+		return nil, err
+	}
+	res, err := algo.solveSSSP(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return g.Result(res.Result), nil
+}
+
+// topLevelDepth returns ceil(log2(n) / t), and at least 1, so that 2^{lt} ≥ n.
+func topLevelDepth(n, t int) int {
+	if n < 2 {
+		return 1
+	}
+	l := int(math.Ceil(math.Log2(float64(n)) / float64(t)))
+	if l < 1 {
+		l = 1
+	}
+	return l
+}
+
+// settleRemaining verifies the result of the recursion: it scans every edge once and,
+// should one still be improvable, runs Dijkstra below B from its tail. It returns the
+// number of vertices whose distance changed, which is 0 unless the recursion missed some.
+func (a *BMSSPAlgorithm[W]) settleRemaining(ctx context.Context) (int, error) {
+	bs := &a.base
+	pq := bs.resetQueue()
+	for _, u := range a.touched {
+		du := a.dist[u]
+		targets, weights := a.graph.Neighbors(u)
+		for i, v := range targets {
+			if newDist := common.AddWeights(du, weights[i]); newDist < a.B && newDist < a.distance(int(v)) {
+				heap.Push(pq, bs.entries.Get(u, du))
+				break
+			}
+		}
+	}
+
+	bs.changed.Reset(a.n)
+	changed := 0
+	for pq.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			a.dropUnsettled()
+			return changed, err
+		}
+		entry := heap.Pop(pq).(*common.DistEntry[W])
+		u := entry.Vertex
+		if entry.Dist > a.distance(u) {
+			continue
+		}
+		a.settled.Add(u)
+		targets, weights := a.graph.Neighbors(u)
+		for i, t := range targets {
+			v := int(t)
+			newDist := common.AddWeights(entry.Dist, weights[i])
+			if newDist < a.B && newDist < a.distance(v) {
+				a.setDist(v, newDist)
+				a.setPred(u, v, weights[i])
+				if bs.changed.Add(v) {
+					changed++
+				}
+				a.settled.Remove(v)
+				heap.Push(pq, bs.entries.Get(v, newDist))
+			}
+		}
+	}
+	return changed, nil
+}
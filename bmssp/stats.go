@@ -0,0 +1,71 @@
+package bmssp
+
+import (
+	"fmt"
+	"playground/common"
+	"strings"
+)
+
+// Stats holds the counters collected by a BMSSP run enabled with WithStats.
+type Stats struct {
+	// Relaxations counts every edge scanned by findPivots, the base case and the pull loop.
+	Relaxations int
+	// RecursionCalls[l] counts calls of bmsspRecursive at level l.
+	RecursionCalls []int
+	// BaseCases counts single-source bounded Dijkstra runs at level 0.
+	BaseCases int
+
+	Pulls         int
+	Inserts       int
+	BatchPrepends int
+
+	// Pivots is the number of pivots returned by findPivots over all calls, and
+	// PivotSources the number of sources those calls started from.
+	Pivots       int
+	PivotSources int
+
+	// ThresholdExits counts pull loops that stopped because |U| reached k·2^{lt}
+	// while D still held entries.
+	ThresholdExits int
+}
+
+func (s *Stats) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "relaxations=%d base_cases=%d", s.Relaxations, s.BaseCases)
+	fmt.Fprintf(&b, " pulls=%d inserts=%d batch_prepends=%d", s.Pulls, s.Inserts, s.BatchPrepends)
+	fmt.Fprintf(&b, " pivots=%d/%d threshold_exits=%d calls_per_level=%v",
+		s.Pivots, s.PivotSources, s.ThresholdExits, s.RecursionCalls)
+	return b.String()
+}
+
+// WithStats enables collection of Stats for the next Solve.
+func (a *BMSSPAlgorithm[W]) WithStats() *BMSSPAlgorithm[W] {
+	a.stats = &Stats{}
+	return a
+}
+
+// Stats returns the counters of the last Solve, or nil if WithStats was not called.
+func (a *BMSSPAlgorithm[W]) Stats() *Stats {
+	return a.stats
+}
+
+// countingQueue wraps a PullQueue and counts calls into it.
+type countingQueue[W common.Weight] struct {
+	PullQueue[W]
+	stats *Stats
+}
+
+func (q countingQueue[W]) Insert(v int, dist W) {
+	q.stats.Inserts++
+	q.PullQueue.Insert(v, dist)
+}
+
+func (q countingQueue[W]) Pull() (W, []int) {
+	q.stats.Pulls++
+	return q.PullQueue.Pull()
+}
+
+func (q countingQueue[W]) BatchPrepend(entries []common.DistEntry[W]) {
+	q.stats.BatchPrepends++
+	q.PullQueue.BatchPrepend(entries)
+}
@@ -0,0 +1,251 @@
+package bmssp
+
+import (
+	"context"
+	"playground/common"
+)
+
+// Workspace answers repeated queries against one graph. It keeps the per-vertex and
+// per-level buffers of a single BMSSPAlgorithm between queries and invalidates them
+// through epoch stamps, so starting a query costs nothing and, once the buffers have
+// grown to their working size, Query does not allocate with the default HeapD.
+type Workspace[W common.Weight] struct {
+	algo     BMSSPAlgorithm[W]
+	repaired int
+}
+
+// NewWorkspace validates g once, converts it into a CSRGraph and binds a Workspace to
+// it. Later changes to g are not seen by the Workspace.
+func NewWorkspace[W common.Weight](g *common.Graph[W]) (*Workspace[W], error) {
+	w := &Workspace[W]{algo: BMSSPAlgorithm[W]{source: g, inf: common.Inf[W]()}}
+	if err := w.algo.validateGraph(); err != nil {
+		return nil, err
+	}
+	w.algo.reset(0, 0, nil)
+	return w, nil
+}
+
+// NewWorkspaceCSR binds a Workspace to g, which needs no validation or conversion.
+func NewWorkspaceCSR[W common.Weight](g *common.CSRGraph[W]) *Workspace[W] {
+	w := &Workspace[W]{algo: BMSSPAlgorithm[W]{graph: g, n: g.NumVertices(), inf: common.Inf[W]()}}
+	w.algo.reset(0, 0, nil)
+	return w
+}
+
+// NewReverseWorkspace is like NewWorkspace but answers single-destination queries:
+// Query then computes the distance from every vertex to the nearest of the given
+// destinations, see BMSSPAlgorithm.WithReverse.
+func NewReverseWorkspace[W common.Weight](g *common.Graph[W]) (*Workspace[W], error) {
+	w := &Workspace[W]{algo: BMSSPAlgorithm[W]{source: g, reverse: true, inf: common.Inf[W]()}}
+	if err := w.algo.validateGraph(); err != nil {
+		return nil, err
+	}
+	w.algo.reset(0, 0, nil)
+	return w, nil
+}
+
+// NewReverseWorkspaceCSR is like NewWorkspaceCSR but answers single-destination queries.
+func NewReverseWorkspaceCSR[W common.Weight](g *common.CSRGraph[W]) *Workspace[W] {
+	w := NewWorkspaceCSR(g.Transpose())
+	w.algo.reverse = true
+	return w
+}
+
+// WithDataStructure selects the implementation of D used by the pull loop.
+func (w *Workspace[W]) WithDataStructure(kind DataStructureKind) *Workspace[W] {
+	w.algo.WithDataStructure(kind)
+	return w
+}
+
+// Query computes the distances from sources of every vertex closer than B, choosing
+// l the same way as SolveSSSP and running the same verification pass, whose count is
+// available from Repaired. Pass common.Inf[W]() as B for an unbounded search. Targets,
+// if given, stop the query as soon as all of them are settled, see
+// BMSSPAlgorithm.WithTargets; the verification pass is then skipped.
+func (w *Workspace[W]) Query(sources []int, B W, targets ...int) error {
+	return w.QueryContext(context.Background(), sources, B, targets...)
+}
+
+// QueryContext is like Query but stops once ctx is done, see BMSSPAlgorithm.SolveContext.
+func (w *Workspace[W]) QueryContext(ctx context.Context, sources []int, B W, targets ...int) error {
+	a := &w.algo
+	_, t := a.kt()
+	a.reset(topLevelDepth(a.n, t), B, sources)
+	a.targets = targets
+	w.repaired = 0
+	if err := a.validateQuery(); err != nil {
+		return err
+	}
+	if err := a.run(ctx); err != nil || a.done {
+		return err
+	}
+	var err error
+	w.repaired, err = a.settleRemaining(ctx)
+	return err
+}
+
+// Repaired returns the number of vertices the verification pass of the last query had
+// to fix, see SSSPResult.Repaired.
+func (w *Workspace[W]) Repaired() int {
+	return w.repaired
+}
+
+// Dist returns the distance of v found by the last query, Inf if v was not reached.
+func (w *Workspace[W]) Dist(v int) W {
+	if v < 0 || v >= w.algo.n {
+		return w.algo.inf
+	}
+	return w.algo.distance(v)
+}
+
+// Result copies the distances and predecessor edges of the last query into a Result.
+func (w *Workspace[W]) Result() *common.Result[W] {
+	return w.algo.result()
+}
+
+// Path returns a shortest path to target found by the last query, see BMSSPAlgorithm.Path.
+func (w *Workspace[W]) Path(target int) ([]int, []common.Edge[W], bool) {
+	return w.algo.Path(target)
+}
+
+// levelScratch holds the buffers of the bmsspRecursive call that is active at one level.
+type levelScratch[W common.Weight] struct {
+	U     []int
+	P     []int
+	W     []int
+	WDist []W // d̂ of W at the end of findPivots.
+	K     []common.DistEntry[W]
+	seen  common.StampSet
+	D     PullQueue[W]
+	kind  DataStructureKind
+}
+
+// queue returns the level's D, creating it on first use or when kind changes.
+func (lv *levelScratch[W]) queue(kind DataStructureKind) PullQueue[W] {
+	if lv.D == nil || lv.kind != kind {
+		lv.D, lv.kind = newPullQueue[W](kind), kind
+	}
+	return lv.D
+}
+
+// baseScratch holds the buffers of baseCase and settleRemaining, which never nest.
+type baseScratch[W common.Weight] struct {
+	pq      common.PriorityQueue[W]
+	entries common.EntryPool[W]
+	seen    common.StampSet
+	changed common.StampSet
+	U       []int
+}
+
+func (bs *baseScratch[W]) resetQueue() *common.PriorityQueue[W] {
+	bs.pq = bs.pq[:0]
+	bs.entries.Reset()
+	return &bs.pq
+}
+
+// pivotScratch holds the buffers of findPivots.
+type pivotScratch struct {
+	inW        common.StampSet
+	inFrontier common.StampSet
+	hasPred    common.StampSet
+	pred       []int // Predecessor of each relaxed vertex in the equality forest.
+	idx        []int // Position of each W member in W.
+
+	frontier, next []int
+	childStart     []int
+	childList      []int
+	iter           []int
+	size           []int
+	state          []uint8 // 0 unvisited, 1 on the DFS stack, 2 done.
+	stack          []int
+}
+
+func (ps *pivotScratch) reset(n int) {
+	if len(ps.pred) < n {
+		ps.pred = make([]int, n)
+		ps.idx = make([]int, n)
+	}
+	ps.inW.Reset(n)
+	ps.hasPred.Reset(n)
+}
+
+// forestRoots appends to P every source whose subtree in the equality forest over W
+// has at least k vertices. Subtree sizes are computed with an iterative DFS; an edge
+// back onto the DFS stack, which only zero-weight cycles can produce, is ignored.
+func (ps *pivotScratch) forestRoots(S, W []int, k int, P []int) []int {
+	m := len(W)
+	for i, v := range W {
+		ps.idx[v] = i
+	}
+
+	// Children of each W member, stored contiguously by parent.
+	ps.childStart = resetInts(ps.childStart, m+1)
+	for _, v := range W {
+		if ps.hasPred.Has(v) && ps.inW.Has(ps.pred[v]) {
+			ps.childStart[ps.idx[ps.pred[v]]+1]++
+		}
+	}
+	for i := 0; i < m; i++ {
+		ps.childStart[i+1] += ps.childStart[i]
+	}
+	ps.childList = resetInts(ps.childList, ps.childStart[m])
+	ps.iter = append(resetInts(ps.iter, 0), ps.childStart[:m]...)
+	for _, v := range W {
+		if ps.hasPred.Has(v) && ps.inW.Has(ps.pred[v]) {
+			p := ps.idx[ps.pred[v]]
+			ps.childList[ps.iter[p]] = ps.idx[v]
+			ps.iter[p]++
+		}
+	}
+	copy(ps.iter, ps.childStart[:m])
+
+	ps.size = resetInts(ps.size, m)
+	if cap(ps.state) < m {
+		ps.state = make([]uint8, m)
+	}
+	ps.state = ps.state[:m]
+	clear(ps.state)
+
+	for _, s := range S {
+		r := ps.idx[s]
+		if ps.state[r] == 0 {
+			ps.state[r], ps.size[r] = 1, 1
+			stack := append(ps.stack[:0], r)
+			for len(stack) > 0 {
+				u := stack[len(stack)-1]
+				if ps.iter[u] < ps.childStart[u+1] {
+					c := ps.childList[ps.iter[u]]
+					ps.iter[u]++
+					switch ps.state[c] {
+					case 0:
+						ps.state[c], ps.size[c] = 1, 1
+						stack = append(stack, c)
+					case 2:
+						ps.size[u] += ps.size[c]
+					}
+					continue
+				}
+				ps.state[u] = 2
+				stack = stack[:len(stack)-1]
+				if len(stack) > 0 {
+					ps.size[stack[len(stack)-1]] += ps.size[u]
+				}
+			}
+			ps.stack = stack
+		}
+		if ps.size[r] >= k {
+			P = append(P, s)
+		}
+	}
+	return P
+}
+
+// resetInts returns s resized to n zeroed elements, reusing its storage if possible.
+func resetInts(s []int, n int) []int {
+	if cap(s) < n {
+		return make([]int, n)
+	}
+	s = s[:n]
+	clear(s)
+	return s
+}
@@ -0,0 +1,161 @@
+package common
+
+import (
+	"fmt"
+	"math"
+)
+
+// DuplicatePolicy decides what GraphBuilder.Build does with parallel edges, that is
+// several edges with the same tail and head.
+type DuplicatePolicy int
+
+const (
+	// KeepDuplicates keeps every parallel edge.
+	KeepDuplicates DuplicatePolicy = iota
+	// KeepLightest keeps only the lightest edge of each group, at the position of the
+	// first one added.
+	KeepLightest
+	// RejectDuplicates makes Build fail with ErrDuplicateEdge.
+	RejectDuplicates
+)
+
+// GraphBuilder collects edges and turns them into a Graph whose Edges and Adj agree.
+// Vertex ids are taken as given; N is one more than the largest id unless it was
+// fixed with WithVertices.
+type GraphBuilder[W Weight] struct {
+	directed   bool
+	n          int
+	fixedN     bool // Whether n was set by WithVertices or has to be inferred.
+	duplicates DuplicatePolicy
+	arcs       []Edge[W]
+}
+
+// NewGraphBuilder returns an empty builder. In an undirected builder AddEdge behaves
+// like AddUndirectedEdge.
+func NewGraphBuilder[W Weight](directed bool) *GraphBuilder[W] {
+	return &GraphBuilder[W]{directed: directed}
+}
+
+// WithVertices fixes N, so that vertices without edges are kept and edges outside
+// [0, n) make Build fail.
+func (b *GraphBuilder[W]) WithVertices(n int) *GraphBuilder[W] {
+	b.n, b.fixedN = n, true
+	return b
+}
+
+// WithDuplicates selects how Build treats parallel edges. The default keeps them all.
+func (b *GraphBuilder[W]) WithDuplicates(p DuplicatePolicy) *GraphBuilder[W] {
+	b.duplicates = p
+	return b
+}
+
+// AddEdge adds an edge from u to v, and its reverse if the builder is undirected.
+func (b *GraphBuilder[W]) AddEdge(u, v int, weight W) *GraphBuilder[W] {
+	if !b.directed {
+		return b.AddUndirectedEdge(u, v, weight)
+	}
+	b.arcs = append(b.arcs, Edge[W]{U: u, V: v, Weight: weight})
+	return b
+}
+
+// AddUndirectedEdge adds an edge from u to v and one from v to u. A self-loop is only
+// added once.
+func (b *GraphBuilder[W]) AddUndirectedEdge(u, v int, weight W) *GraphBuilder[W] {
+	b.arcs = append(b.arcs, Edge[W]{U: u, V: v, Weight: weight})
+	if u != v {
+		b.arcs = append(b.arcs, Edge[W]{U: v, V: u, Weight: weight})
+	}
+	return b
+}
+
+// Build validates the collected edges and returns a new Graph. N may not exceed
+// math.MaxInt32, the vertex limit of CSRGraph. The builder is left unchanged and can be
+// extended and built again.
+func (b *GraphBuilder[W]) Build() (*Graph[W], error) {
+	n := b.n
+	if b.fixedN && n < 0 {
+		return nil, &ParameterError{Name: "N", Value: n, Reason: "must be non-negative"}
+	}
+	if !b.fixedN {
+		n = 0
+		for _, e := range b.arcs {
+			// Clamped, so that the largest int cannot overflow into a small N.
+			n = max(n, min(e.U, math.MaxInt32)+1, min(e.V, math.MaxInt32)+1)
+		}
+	}
+	if n > math.MaxInt32 {
+		return nil, &ParameterError{Name: "N", Value: n, Reason: fmt.Sprintf("graphs support at most %d vertices", math.MaxInt32)}
+	}
+	g := &Graph[W]{N: n, Directed: b.directed}
+	for _, e := range b.arcs {
+		if err := validateEdge(g, e); err != nil {
+			return nil, err
+		}
+	}
+
+	arcs, err := b.dedup()
+	if err != nil {
+		return nil, err
+	}
+
+	g.Edges, g.Adj = groupByTail(n, arcs)
+	return g, nil
+}
+
+// groupByTail sorts arcs by tail with a stable counting sort and returns them together
+// with adjacency lists that alias the sorted slice.
+func groupByTail[W Weight](n int, arcs []Edge[W]) ([]Edge[W], map[int][]Edge[W]) {
+	start := make([]int, n+1)
+	for _, e := range arcs {
+		start[e.U+1]++
+	}
+	for u := 0; u < n; u++ {
+		start[u+1] += start[u]
+	}
+	edges := make([]Edge[W], len(arcs))
+	next := append([]int(nil), start[:n]...)
+	for _, e := range arcs {
+		edges[next[e.U]] = e
+		next[e.U]++
+	}
+
+	adj := make(map[int][]Edge[W], n)
+	for u := 0; u < n; u++ {
+		if lo, hi := start[u], start[u+1]; hi > lo {
+			adj[u] = edges[lo:hi:hi]
+		}
+	}
+	return edges, adj
+}
+
+// dedup applies the duplicate policy and returns the arcs to keep.
+func (b *GraphBuilder[W]) dedup() ([]Edge[W], error) {
+	if b.duplicates == KeepDuplicates {
+		return b.arcs, nil
+	}
+	type key struct{ u, v int }
+	first := make(map[key]int, len(b.arcs))
+	arcs := make([]Edge[W], 0, len(b.arcs))
+	for _, e := range b.arcs {
+		i, seen := first[key{e.U, e.V}]
+		switch {
+		case !seen:
+			first[key{e.U, e.V}] = len(arcs)
+			arcs = append(arcs, e)
+		case b.duplicates == RejectDuplicates:
+			return nil, &EdgeError[W]{Edge: e, Err: ErrDuplicateEdge}
+		case e.Weight < arcs[i].Weight:
+			arcs[i].Weight = e.Weight
+		}
+	}
+	return arcs, nil
+}
+
+// BuildCSR is like Build but returns the graph in compressed sparse row form.
+func (b *GraphBuilder[W]) BuildCSR() (*CSRGraph[W], error) {
+	g, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return NewCSRGraph(g)
+}
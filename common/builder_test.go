@@ -0,0 +1,22 @@
+package common_test
+
+import (
+	"errors"
+	"math"
+	"playground/common"
+	"testing"
+)
+
+func TestGraphBuilder_VertexLimit(t *testing.T) {
+	builders := map[string]*common.GraphBuilder[float64]{
+		"fixed":    common.NewGraphBuilder[float64](true).WithVertices(math.MaxInt32 + 1),
+		"inferred": common.NewGraphBuilder[float64](true).AddEdge(0, math.MaxInt32, 1),
+		"max int":  common.NewGraphBuilder[float64](false).AddEdge(math.MaxInt, 0, 1),
+	}
+	for name, b := range builders {
+		var pe *common.ParameterError
+		if _, err := b.Build(); !errors.As(err, &pe) || pe.Name != "N" {
+			t.Errorf("%s: expected a ParameterError for N, got %v", name, err)
+		}
+	}
+}
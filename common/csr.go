@@ -0,0 +1,164 @@
+package common
+
+import (
+	"fmt"
+	"iter"
+	"math"
+)
+
+// CSRGraph is an immutable directed graph in compressed sparse row form. The out-edges
+// of u occupy positions offsets[u] to offsets[u+1] of the targets and weights arrays,
+// so a neighbor scan is a walk over two contiguous slices instead of a map lookup.
+// A CSRGraph can only be built from validated input and never changes afterwards, so
+// solvers may share one between goroutines without validating it again.
+type CSRGraph[W Weight] struct {
+	offsets []int
+	targets []int32
+	weights []W
+}
+
+// NewCSRGraph validates g and converts its adjacency lists into a CSRGraph. The order
+// of the out-edges of each vertex is preserved. Edges is not consulted beyond
+// validation, since every entry of it also appears in Adj.
+func NewCSRGraph[W Weight](g *Graph[W]) (*CSRGraph[W], error) {
+	if err := ValidateGraph(g); err != nil {
+		return nil, err
+	}
+	if g.N > math.MaxInt32 {
+		return nil, &ParameterError{Name: "N", Value: g.N, Reason: fmt.Sprintf("CSRGraph supports at most %d vertices", math.MaxInt32)}
+	}
+
+	m := 0
+	for u := 0; u < g.N; u++ {
+		m += len(g.Adj[u])
+	}
+	c := &CSRGraph[W]{
+		offsets: make([]int, g.N+1),
+		targets: make([]int32, 0, m),
+		weights: make([]W, 0, m),
+	}
+	for u := 0; u < g.N; u++ {
+		for _, e := range g.Adj[u] {
+			c.targets = append(c.targets, int32(e.V))
+			c.weights = append(c.weights, e.Weight)
+		}
+		c.offsets[u+1] = len(c.targets)
+	}
+	return c, nil
+}
+
+// NewCSRGraphFromArrays wraps arrays that are already in CSR form without copying
+// them: offsets holds N+1 non-decreasing positions starting at 0, and targets and
+// weights hold one entry per edge. The arrays are checked in O(N+M) like the edges of
+// a Graph and must not be modified afterwards.
+func NewCSRGraphFromArrays[W Weight](offsets []int, targets []int32, weights []W) (*CSRGraph[W], error) {
+	if len(offsets) == 0 || offsets[0] != 0 {
+		return nil, &ParameterError{Name: "offsets", Value: len(offsets), Reason: "must start with 0"}
+	}
+	n := len(offsets) - 1
+	if len(targets) != len(weights) || offsets[n] != len(targets) {
+		return nil, &ParameterError{Name: "offsets", Value: offsets[n], Reason: fmt.Sprintf("must end at the edge count %d (%d weights)", len(targets), len(weights))}
+	}
+	g := &Graph[W]{N: n}
+	for u := 0; u < n; u++ {
+		if offsets[u+1] < offsets[u] || offsets[u+1] > offsets[n] {
+			return nil, &VertexError{Vertex: u, Err: ErrInconsistentGraph}
+		}
+		for i := offsets[u]; i < offsets[u+1]; i++ {
+			if err := validateEdge(g, Edge[W]{U: u, V: int(targets[i]), Weight: weights[i]}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &CSRGraph[W]{offsets: offsets, targets: targets, weights: weights}, nil
+}
+
+// Arrays returns the offsets, targets and weights backing c, see NewCSRGraphFromArrays.
+// They must not be modified.
+func (c *CSRGraph[W]) Arrays() (offsets []int, targets []int32, weights []W) {
+	return c.offsets, c.targets, c.weights
+}
+
+// NumVertices returns the number of vertices.
+func (c *CSRGraph[W]) NumVertices() int {
+	if c == nil || len(c.offsets) == 0 {
+		return 0
+	}
+	return len(c.offsets) - 1
+}
+
+// NumEdges returns the number of directed edges.
+func (c *CSRGraph[W]) NumEdges() int {
+	return len(c.targets)
+}
+
+// Degree returns the out-degree of u.
+func (c *CSRGraph[W]) Degree(u int) int {
+	return c.offsets[u+1] - c.offsets[u]
+}
+
+// Neighbors returns the heads and weights of the out-edges of u as parallel slices.
+// They alias the graph's storage and must not be modified.
+func (c *CSRGraph[W]) Neighbors(u int) ([]int32, []W) {
+	lo, hi := c.offsets[u], c.offsets[u+1]
+	return c.targets[lo:hi:hi], c.weights[lo:hi:hi]
+}
+
+// OutEdges iterates over the out-edges of u.
+func (c *CSRGraph[W]) OutEdges(u int) iter.Seq[Edge[W]] {
+	return func(yield func(Edge[W]) bool) {
+		targets, weights := c.Neighbors(u)
+		for i, v := range targets {
+			if !yield(Edge[W]{U: u, V: int(v), Weight: weights[i]}) {
+				return
+			}
+		}
+	}
+}
+
+// Transpose returns the graph with every edge reversed, which serves as the in-adjacency
+// index of c: the out-edges of v in the transpose are the in-edges of v in c, ordered
+// by tail vertex and, for equal tails, by their position in c.
+func (c *CSRGraph[W]) Transpose() *CSRGraph[W] {
+	n, m := c.NumVertices(), c.NumEdges()
+	t := &CSRGraph[W]{
+		offsets: make([]int, n+1),
+		targets: make([]int32, m),
+		weights: make([]W, m),
+	}
+	for _, v := range c.targets {
+		t.offsets[v+1]++
+	}
+	for v := 0; v < n; v++ {
+		t.offsets[v+1] += t.offsets[v]
+	}
+	next := make([]int, n)
+	copy(next, t.offsets[:n])
+	for u := 0; u < n; u++ {
+		targets, weights := c.Neighbors(u)
+		for i, v := range targets {
+			t.targets[next[v]] = int32(u)
+			t.weights[next[v]] = weights[i]
+			next[v]++
+		}
+	}
+	return t
+}
+
+// Graph converts c back into the map-based representation, with Edges listing every
+// edge once in CSR order. The result is marked as directed, since a CSRGraph does not
+// record whether its edges come in symmetric pairs.
+func (c *CSRGraph[W]) Graph() *Graph[W] {
+	n := c.NumVertices()
+	g := &Graph[W]{N: n, Edges: make([]Edge[W], 0, c.NumEdges()), Adj: make(map[int][]Edge[W], n), Directed: true}
+	for u := 0; u < n; u++ {
+		start := len(g.Edges)
+		for e := range c.OutEdges(u) {
+			g.Edges = append(g.Edges, e)
+		}
+		if len(g.Edges) > start {
+			g.Adj[u] = g.Edges[start:len(g.Edges):len(g.Edges)]
+		}
+	}
+	return g
+}
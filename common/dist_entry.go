@@ -1,7 +1,7 @@
 package common
 
-type DistEntry struct {
+type DistEntry[W Weight] struct {
 	Vertex int
-	Dist   float64
+	Dist   W
 	Index  int
 }
@@ -1,6 +1,6 @@
 package common
 
-type Edge struct {
+type Edge[W Weight] struct {
 	U, V   int
-	Weight float64
+	Weight W
 }
@@ -1,7 +1,24 @@
 package common
 
-type Graph struct {
-	N     int
-	Edges []Edge
-	Adj   map[int][]Edge
+// Graph is a weighted graph over the dense vertex ids 0..N-1. Adj holds the out-edges
+// of every vertex; an undirected edge is stored once in each direction. Graphs built
+// by GraphBuilder list exactly the edges of Adj in Edges, grouped by tail vertex.
+// A Graph literal of a directed graph must set Directed, since Check and the exporters
+// treat a graph without it as undirected.
+type Graph[W Weight] struct {
+	N        int
+	Edges    []Edge[W]
+	Adj      map[int][]Edge[W]
+	Directed bool // Set by GraphBuilder; if false, every edge has a reverse edge of equal weight.
+}
+
+// NumVertices returns N.
+func (g *Graph[W]) NumVertices() int {
+	return g.N
+}
+
+// VertexCounter is implemented by every graph representation whose vertices are the
+// dense ids 0..NumVertices()-1.
+type VertexCounter interface {
+	NumVertices() int
 }
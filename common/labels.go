@@ -0,0 +1,190 @@
+package common
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// LabelError reports a label that is not known to a LabeledGraph.
+type LabelError struct {
+	Label any
+	Err   error
+}
+
+func (e *LabelError) Error() string {
+	return fmt.Sprintf("label %v: %v", e.Label, e.Err)
+}
+
+func (e *LabelError) Unwrap() error { return e.Err }
+
+// Labels interns keys into the dense ids 0..Len()-1 in order of first appearance.
+type Labels[K comparable] struct {
+	ids  map[K]int
+	keys []K
+}
+
+// NewLabels returns an empty set of labels.
+func NewLabels[K comparable]() *Labels[K] {
+	return &Labels[K]{ids: make(map[K]int)}
+}
+
+// Intern returns the id of k, assigning the next free id if k is new.
+func (l *Labels[K]) Intern(k K) int {
+	if id, ok := l.ids[k]; ok {
+		return id
+	}
+	id := len(l.keys)
+	l.ids[k] = id
+	l.keys = append(l.keys, k)
+	return id
+}
+
+// ID returns the id of k and whether k is known.
+func (l *Labels[K]) ID(k K) (int, bool) {
+	id, ok := l.ids[k]
+	return id, ok
+}
+
+// Key returns the label of id, which must lie within [0, Len()).
+func (l *Labels[K]) Key(id int) K {
+	return l.keys[id]
+}
+
+// Len returns the number of interned labels.
+func (l *Labels[K]) Len() int {
+	return len(l.keys)
+}
+
+// Clone returns an independent copy of l.
+func (l *Labels[K]) Clone() *Labels[K] {
+	return &Labels[K]{ids: maps.Clone(l.ids), keys: slices.Clone(l.keys)}
+}
+
+// IDs translates keys into ids. Unknown keys are reported as a LabelError wrapping
+// ErrUnknownLabel.
+func (l *Labels[K]) IDs(keys []K) ([]int, error) {
+	ids := make([]int, len(keys))
+	for i, k := range keys {
+		id, ok := l.ids[k]
+		if !ok {
+			return nil, &LabelError{Label: k, Err: ErrUnknownLabel}
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// LabeledGraph is a Graph whose vertices carry labels. The solvers run on Graph; the
+// labels only translate sources, targets and results.
+type LabeledGraph[K comparable, W Weight] struct {
+	*Graph[W]
+	Labels *Labels[K]
+}
+
+// Result attaches the labels of g to a result computed on g.Graph.
+func (g *LabeledGraph[K, W]) Result(r *Result[W]) *LabeledResult[K, W] {
+	return &LabeledResult[K, W]{Result: r, labels: g.Labels}
+}
+
+// LabeledResult is a Result addressed by label. The id-based methods of Result stay
+// available through the embedded field.
+type LabeledResult[K comparable, W Weight] struct {
+	*Result[W]
+	labels *Labels[K]
+}
+
+// DistOf returns the distance of the vertex labelled k, Inf[W]() if it is unreachable
+// or k is unknown.
+func (r *LabeledResult[K, W]) DistOf(k K) W {
+	id, ok := r.labels.ID(k)
+	if !ok {
+		return Inf[W]()
+	}
+	return r.Result.Dist(id)
+}
+
+// PathTo returns the labels of a shortest path to the vertex labelled k, see Result.Path.
+func (r *LabeledResult[K, W]) PathTo(k K) ([]K, bool) {
+	id, ok := r.labels.ID(k)
+	if !ok {
+		return nil, false
+	}
+	vertices, _, ok := r.Result.Path(id)
+	if !ok {
+		return nil, false
+	}
+	path := make([]K, len(vertices))
+	for i, v := range vertices {
+		path[i] = r.labels.Key(v)
+	}
+	return path, true
+}
+
+// ReachedLabels iterates over the labels of the reachable vertices and their distances.
+func (r *LabeledResult[K, W]) ReachedLabels() iter.Seq2[K, W] {
+	return func(yield func(K, W) bool) {
+		for v, d := range r.Result.Reached() {
+			if !yield(r.labels.Key(v), d) {
+				return
+			}
+		}
+	}
+}
+
+// LabelMap returns the distances of all vertices keyed by label.
+func (r *LabeledResult[K, W]) LabelMap() map[K]W {
+	m := make(map[K]W, r.Len())
+	for v, d := range r.All() {
+		m[r.labels.Key(v)] = d
+	}
+	return m
+}
+
+// LabeledGraphBuilder is a GraphBuilder whose edges name their endpoints by label.
+// Labels are interned in order of first appearance.
+type LabeledGraphBuilder[K comparable, W Weight] struct {
+	labels  *Labels[K]
+	builder *GraphBuilder[W]
+}
+
+// NewLabeledGraphBuilder returns an empty builder, see NewGraphBuilder.
+func NewLabeledGraphBuilder[K comparable, W Weight](directed bool) *LabeledGraphBuilder[K, W] {
+	return &LabeledGraphBuilder[K, W]{labels: NewLabels[K](), builder: NewGraphBuilder[W](directed)}
+}
+
+// WithDuplicates selects how Build treats parallel edges, see GraphBuilder.WithDuplicates.
+func (b *LabeledGraphBuilder[K, W]) WithDuplicates(p DuplicatePolicy) *LabeledGraphBuilder[K, W] {
+	b.builder.WithDuplicates(p)
+	return b
+}
+
+// AddVertex interns k, so that it is part of the graph even without edges.
+func (b *LabeledGraphBuilder[K, W]) AddVertex(k K) *LabeledGraphBuilder[K, W] {
+	b.labels.Intern(k)
+	return b
+}
+
+// AddEdge adds an edge from u to v, see GraphBuilder.AddEdge.
+func (b *LabeledGraphBuilder[K, W]) AddEdge(u, v K, weight W) *LabeledGraphBuilder[K, W] {
+	b.builder.AddEdge(b.labels.Intern(u), b.labels.Intern(v), weight)
+	return b
+}
+
+// AddUndirectedEdge adds an edge in each direction, see GraphBuilder.AddUndirectedEdge.
+func (b *LabeledGraphBuilder[K, W]) AddUndirectedEdge(u, v K, weight W) *LabeledGraphBuilder[K, W] {
+	b.builder.AddUndirectedEdge(b.labels.Intern(u), b.labels.Intern(v), weight)
+	return b
+}
+
+// Build returns a LabeledGraph with one vertex per label. The graph gets a copy of the
+// labels, so vertices added to the builder afterwards do not change it, while graphs
+// built later keep the ids of earlier ones.
+func (b *LabeledGraphBuilder[K, W]) Build() (*LabeledGraph[K, W], error) {
+	g, err := b.builder.WithVertices(b.labels.Len()).Build()
+	if err != nil {
+		return nil, err
+	}
+	return &LabeledGraph[K, W]{Graph: g, Labels: b.labels.Clone()}, nil
+}
@@ -0,0 +1,27 @@
+package common_test
+
+import (
+	"playground/common"
+	"testing"
+)
+
+func TestLabeledGraphBuilder_BuildCopiesLabels(t *testing.T) {
+	b := common.NewLabeledGraphBuilder[string, float64](true).AddEdge("a", "b", 1)
+	first, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() returned an error: %v", err)
+	}
+	second, err := b.AddVertex("c").AddEdge("b", "d", 2).Build()
+	if err != nil {
+		t.Fatalf("Build() returned an error: %v", err)
+	}
+	if first.Labels.Len() != first.N {
+		t.Errorf("expected %d labels in the first graph, got %d", first.N, first.Labels.Len())
+	}
+	if _, ok := first.Labels.ID("c"); ok {
+		t.Error("expected a vertex added after Build to be unknown to the first graph")
+	}
+	if id, _ := second.Labels.ID("b"); id != 1 || second.N != 4 {
+		t.Errorf("expected later graphs to keep the ids of earlier ones, got id %d in N=%d", id, second.N)
+	}
+}
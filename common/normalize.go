@@ -0,0 +1,265 @@
+package common
+
+import (
+	"fmt"
+	"slices"
+)
+
+// IssueKind classifies a problem found by Check or Normalize.
+type IssueKind int
+
+const (
+	// IssueVertexOutOfRange marks an Adj key or edge endpoint outside [0, N).
+	IssueVertexOutOfRange IssueKind = iota
+	// IssueMisfiledEdge marks an edge stored in Adj under a vertex other than its tail.
+	IssueMisfiledEdge
+	// IssueInvalidWeight marks an edge whose weight is NaN or infinite.
+	IssueInvalidWeight
+	// IssueNegativeWeight marks an edge with a weight below zero.
+	IssueNegativeWeight
+	// IssueSelfLoop marks an edge from a vertex to itself.
+	IssueSelfLoop
+	// IssueParallelEdge marks an edge with the same tail and head as an earlier one.
+	IssueParallelEdge
+	// IssueMissingFromAdj marks an entry of Edges that Adj does not hold.
+	IssueMissingFromAdj
+	// IssueMissingFromEdges marks an entry of Adj that Edges does not list.
+	IssueMissingFromEdges
+	// IssueAsymmetricEdge marks an edge of an undirected graph without a reverse edge
+	// of equal weight.
+	IssueAsymmetricEdge
+)
+
+var issueNames = [...]string{
+	IssueVertexOutOfRange: "vertex_out_of_range",
+	IssueMisfiledEdge:     "misfiled_edge",
+	IssueInvalidWeight:    "invalid_weight",
+	IssueNegativeWeight:   "negative_weight",
+	IssueSelfLoop:         "self_loop",
+	IssueParallelEdge:     "parallel_edge",
+	IssueMissingFromAdj:   "missing_from_adj",
+	IssueMissingFromEdges: "missing_from_edges",
+	IssueAsymmetricEdge:   "asymmetric_edge",
+}
+
+func (k IssueKind) String() string {
+	if k < 0 || int(k) >= len(issueNames) {
+		return fmt.Sprintf("IssueKind(%d)", int(k))
+	}
+	return issueNames[k]
+}
+
+// MarshalText encodes the kind by name, so reports serialize to readable JSON.
+func (k IssueKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Issue is a single problem found in a graph. Vertex is the offending Adj key for an
+// out-of-range key and the tail of Edge otherwise; Edge is nil only for Adj keys.
+type Issue[W Weight] struct {
+	Kind   IssueKind `json:"kind"`
+	Vertex int       `json:"vertex"`
+	Edge   *Edge[W]  `json:"edge,omitempty"`
+	Fixed  bool      `json:"fixed"` // Whether Normalize removed the problem from its result.
+}
+
+// Report lists the problems found by Check or Normalize in the order they were found.
+type Report[W Weight] struct {
+	Issues []Issue[W] `json:"issues"`
+}
+
+// Count returns the number of issues of the given kind.
+func (r *Report[W]) Count(kind IssueKind) int {
+	n := 0
+	for _, is := range r.Issues {
+		if is.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// OK reports whether every issue was fixed, which holds trivially for a clean graph.
+func (r *Report[W]) OK() bool {
+	for _, is := range r.Issues {
+		if !is.Fixed {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Report[W]) addEdge(kind IssueKind, e Edge[W], fixed bool) {
+	r.Issues = append(r.Issues, Issue[W]{Kind: kind, Vertex: e.U, Edge: &e, Fixed: fixed})
+}
+
+// EdgeSource selects which representation of a graph Normalize trusts.
+type EdgeSource int
+
+const (
+	// FromAdj rebuilds Edges from the adjacency lists.
+	FromAdj EdgeSource = iota
+	// FromEdges rebuilds Adj from the edge list. A graph with an empty edge list is
+	// always rebuilt from Adj.
+	FromEdges
+)
+
+// NormalizeOptions selects the fixes Normalize applies. The zero value applies all of
+// them and rebuilds Edges from Adj.
+type NormalizeOptions struct {
+	Source            EdgeSource
+	KeepSelfLoops     bool
+	KeepParallelEdges bool // If false, only the lightest edge of each group is kept.
+}
+
+// Check reports the problems of g without changing it. It inspects Adj, compares it
+// with Edges and, for undirected graphs, verifies that every edge has a reverse edge.
+func Check[W Weight](g *Graph[W]) *Report[W] {
+	_, r := normalize(g, NormalizeOptions{}, false)
+	return r
+}
+
+// Normalize returns a copy of g with the problems Check would report fixed where
+// possible: edges out of range are dropped, misfiled edges are filed under their tail,
+// self-loops and the heavier of parallel edges are removed unless opts keeps them, and
+// Edges and Adj are rebuilt from opts.Source so that they list exactly the same edges,
+// grouped by tail as GraphBuilder does. Invalid weights and missing reverse edges are
+// reported but left in place. g itself is not modified.
+func Normalize[W Weight](g *Graph[W], opts NormalizeOptions) (*Graph[W], *Report[W]) {
+	return normalize(g, opts, true)
+}
+
+func normalize[W Weight](g *Graph[W], opts NormalizeOptions, fix bool) (*Graph[W], *Report[W]) {
+	r := &Report[W]{}
+	if g == nil {
+		return nil, r
+	}
+	n := max(g.N, 0)
+
+	// Flatten Adj in key order, so reports do not depend on map iteration.
+	keys := make([]int, 0, len(g.Adj))
+	for u, adj := range g.Adj {
+		if len(adj) > 0 {
+			keys = append(keys, u)
+		}
+	}
+	slices.Sort(keys)
+	var adjArcs []Edge[W]
+	for _, u := range keys {
+		if u < 0 || u >= n {
+			r.Issues = append(r.Issues, Issue[W]{Kind: IssueVertexOutOfRange, Vertex: u, Fixed: fix})
+			continue
+		}
+		for _, e := range g.Adj[u] {
+			if e.U != u {
+				r.addEdge(IssueMisfiledEdge, e, fix)
+			}
+			adjArcs = append(adjArcs, e)
+		}
+	}
+
+	source := adjArcs
+	if opts.Source == FromEdges && len(g.Edges) > 0 {
+		source = g.Edges
+	}
+	for _, e := range source {
+		if inRange(e, n) && invalidWeight(e.Weight) {
+			r.addEdge(IssueInvalidWeight, e, false)
+		}
+	}
+
+	// Compare both representations as multisets. Edges with invalid weights were
+	// reported above and are skipped: a NaN key never finds itself in the map.
+	if len(g.Edges) > 0 {
+		count := make(map[Edge[W]]int, len(adjArcs))
+		for _, e := range adjArcs {
+			if !invalidWeight(e.Weight) {
+				count[e]++
+			}
+		}
+		for _, e := range g.Edges {
+			if invalidWeight(e.Weight) {
+				continue
+			}
+			if count[e] == 0 {
+				r.addEdge(IssueMissingFromAdj, e, fix)
+			}
+			count[e]--
+		}
+		for _, e := range adjArcs {
+			if count[e] > 0 {
+				r.addEdge(IssueMissingFromEdges, e, fix)
+				count[e]--
+			}
+		}
+	}
+
+	type key struct{ u, v int }
+	first := make(map[key]int, len(source))
+	arcs := make([]Edge[W], 0, len(source))
+	for _, e := range source {
+		switch {
+		case !inRange(e, n):
+			r.addEdge(IssueVertexOutOfRange, e, fix)
+			continue
+		case invalidWeight(e.Weight):
+			// Already reported.
+		case e.Weight < 0:
+			r.addEdge(IssueNegativeWeight, e, false)
+		}
+		if e.U == e.V {
+			r.addEdge(IssueSelfLoop, e, fix && !opts.KeepSelfLoops)
+			if fix && !opts.KeepSelfLoops {
+				continue
+			}
+		}
+		i, seen := first[key{e.U, e.V}]
+		if !seen {
+			first[key{e.U, e.V}] = len(arcs)
+			arcs = append(arcs, e)
+			continue
+		}
+		dropped := e
+		if e.Weight < arcs[i].Weight {
+			dropped = arcs[i]
+		}
+		r.addEdge(IssueParallelEdge, dropped, fix && !opts.KeepParallelEdges)
+		if fix && !opts.KeepParallelEdges {
+			arcs[i].Weight = min(arcs[i].Weight, e.Weight)
+		} else {
+			arcs = append(arcs, e)
+		}
+	}
+
+	if !g.Directed {
+		checkSymmetry(arcs, r)
+	}
+	out := &Graph[W]{N: n, Directed: g.Directed}
+	out.Edges, out.Adj = groupByTail(n, arcs)
+	return out, r
+}
+
+func inRange[W Weight](e Edge[W], n int) bool {
+	return e.U >= 0 && e.U < n && e.V >= 0 && e.V < n
+}
+
+// invalidWeight reports whether w is NaN, the only value unequal to itself, or infinite.
+func invalidWeight[W Weight](w W) bool {
+	return w != w || IsInf(w)
+}
+
+// checkSymmetry reports every edge, once per excess copy, that has more copies than
+// its reverse edge of the same weight.
+func checkSymmetry[W Weight](arcs []Edge[W], r *Report[W]) {
+	count := make(map[Edge[W]]int, len(arcs))
+	for _, e := range arcs {
+		count[e]++
+	}
+	for _, e := range arcs {
+		rev := Edge[W]{U: e.V, V: e.U, Weight: e.Weight}
+		if e.U != e.V && count[e] > count[rev] {
+			r.addEdge(IssueAsymmetricEdge, e, false)
+			count[e]--
+		}
+	}
+}
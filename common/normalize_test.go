@@ -0,0 +1,104 @@
+package common_test
+
+import (
+	"encoding/json"
+	"math"
+	"playground/common"
+	"playground/dijkstra"
+	"playground/internal/graphtest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	g := &common.Graph[float64]{
+		N: 3,
+		Adj: map[int][]common.Edge[float64]{
+			0: {{U: 0, V: 1, Weight: 4}, {U: 0, V: 1, Weight: 2}, {U: 0, V: 0, Weight: 1}},
+			1: {{U: 1, V: 2, Weight: 3}},
+			5: {{U: 5, V: 0, Weight: 1}},
+		},
+		Edges:    []common.Edge[float64]{{U: 0, V: 1, Weight: 4}},
+		Directed: true,
+	}
+
+	report := common.Check(g)
+	for kind, want := range map[common.IssueKind]int{
+		common.IssueVertexOutOfRange: 1,
+		common.IssueSelfLoop:         1,
+		common.IssueParallelEdge:     1,
+		common.IssueMissingFromEdges: 3,
+		common.IssueMissingFromAdj:   0,
+		common.IssueAsymmetricEdge:   0,
+	} {
+		if got := report.Count(kind); got != want {
+			t.Errorf("Check: expected %d %v issues, got %d", want, kind, got)
+		}
+	}
+	if report.OK() {
+		t.Error("Check: expected unfixed issues")
+	}
+	if err := common.ValidateGraph(g); err == nil {
+		t.Fatal("expected ValidateGraph to reject the raw graph")
+	}
+
+	fixed, report := common.Normalize(g, common.NormalizeOptions{})
+	if !report.OK() {
+		t.Fatalf("Normalize: expected every issue to be fixed, got %+v", report.Issues)
+	}
+	if err := common.ValidateGraph(fixed); err != nil {
+		t.Fatalf("Normalize returned an invalid graph: %v", err)
+	}
+	want := []common.Edge[float64]{{U: 0, V: 1, Weight: 2}, {U: 1, V: 2, Weight: 3}}
+	if !slices.Equal(fixed.Edges, want) {
+		t.Errorf("expected edges %v, got %v", want, fixed.Edges)
+	}
+	res, err := dijkstra.NewDijkstraAlgorithm(fixed, []int{0}, nil).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	if res.Dist(2) != 5 {
+		t.Errorf("expected dist(2)=5, got %f", res.Dist(2))
+	}
+
+	js, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned an error: %v", err)
+	}
+	if !strings.Contains(string(js), `"kind":"self_loop"`) {
+		t.Errorf("expected the JSON report to name issue kinds, got %s", js)
+	}
+
+	// An undirected graph missing a reverse edge is reported but not repaired.
+	asym := graphtest.MustBuild(common.NewGraphBuilder[float64](false).AddEdge(0, 1, 1))
+	asym.Adj[1] = nil
+	_, report = common.Normalize(asym, common.NormalizeOptions{Source: common.FromEdges})
+	if report.Count(common.IssueAsymmetricEdge) != 0 || report.Count(common.IssueMissingFromAdj) != 1 {
+		t.Errorf("expected the Edges list to restore symmetry, got %+v", report.Issues)
+	}
+	_, report = common.Normalize(asym, common.NormalizeOptions{})
+	if report.Count(common.IssueAsymmetricEdge) != 1 || report.OK() {
+		t.Errorf("expected one unfixed asymmetric edge, got %+v", report.Issues)
+	}
+}
+
+func TestCheck_InvalidWeights(t *testing.T) {
+	// A NaN edge listed in both Adj and Edges is invalid, but not missing from either.
+	edges := []common.Edge[float64]{{U: 0, V: 1, Weight: math.NaN()}, {U: 1, V: 2, Weight: math.Inf(1)}, {U: 2, V: 0, Weight: 1}}
+	g := &common.Graph[float64]{
+		N:        3,
+		Edges:    edges,
+		Adj:      map[int][]common.Edge[float64]{0: edges[0:1], 1: edges[1:2], 2: edges[2:3]},
+		Directed: true,
+	}
+	for _, source := range []common.EdgeSource{common.FromAdj, common.FromEdges} {
+		_, report := common.Normalize(g, common.NormalizeOptions{Source: source})
+		if len(report.Issues) != 2 || report.Count(common.IssueInvalidWeight) != 2 {
+			t.Errorf("source %d: expected two invalid_weight issues only, got %+v", source, report.Issues)
+		}
+		if report.Issues[0].Kind != common.IssueInvalidWeight || report.OK() {
+			t.Errorf("source %d: expected unfixed invalid weights to be reported first, got %+v", source, report.Issues)
+		}
+	}
+}
@@ -1,29 +1,29 @@
 package common
 
-type PriorityQueue []*DistEntry
+type PriorityQueue[W Weight] []*DistEntry[W]
 
-func (pq *PriorityQueue) Len() int {
+func (pq *PriorityQueue[W]) Len() int {
 	return len(*pq)
 }
 
-func (pq *PriorityQueue) Less(i, j int) bool {
+func (pq *PriorityQueue[W]) Less(i, j int) bool {
 	return (*pq)[i].Dist < (*pq)[j].Dist
 }
 
-func (pq *PriorityQueue) Swap(i, j int) {
+func (pq *PriorityQueue[W]) Swap(i, j int) {
 	(*pq)[i], (*pq)[j] = (*pq)[j], (*pq)[i]
 	(*pq)[i].Index = i
 	(*pq)[j].Index = j
 }
 
-func (pq *PriorityQueue) Push(x interface{}) {
+func (pq *PriorityQueue[W]) Push(x interface{}) {
 	n := len(*pq)
-	item := x.(*DistEntry)
+	item := x.(*DistEntry[W])
 	item.Index = n
 	*pq = append(*pq, item)
 }
 
-func (pq *PriorityQueue) Pop() interface{} {
+func (pq *PriorityQueue[W]) Pop() interface{} {
 	old := *pq
 	n := len(old)
 	item := old[n-1]
@@ -0,0 +1,281 @@
+package common
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Permutation maps the vertex ids of a graph to those of a reordered copy.
+type Permutation struct {
+	newID []int // newID[old] is the id of the original vertex old in the reordered graph.
+	oldID []int // oldID[new] is the original id of vertex new.
+}
+
+// NewPermutation checks that order lists every id in [0, len(order)) exactly once and
+// returns the permutation that gives order[i] the new id i.
+func NewPermutation(order []int) (*Permutation, error) {
+	p := &Permutation{newID: make([]int, len(order)), oldID: slices.Clone(order)}
+	for i := range p.newID {
+		p.newID[i] = -1
+	}
+	for i, old := range order {
+		if old < 0 || old >= len(order) {
+			return nil, &VertexError{Vertex: old, Err: ErrVertexOutOfRange}
+		}
+		if p.newID[old] >= 0 {
+			return nil, &ParameterError{Name: "order", Value: old, Reason: "lists a vertex twice"}
+		}
+		p.newID[old] = i
+	}
+	return p, nil
+}
+
+// Len returns the number of vertices the permutation covers.
+func (p *Permutation) Len() int {
+	return len(p.oldID)
+}
+
+// NewID returns the id of the original vertex old in the reordered graph.
+func (p *Permutation) NewID(old int) int {
+	return p.newID[old]
+}
+
+// OldID returns the original id of the reordered vertex v.
+func (p *Permutation) OldID(v int) int {
+	return p.oldID[v]
+}
+
+// NewIDs translates original ids, such as sources, into reordered ids. Ids outside
+// [0, Len()) are reported as a VertexError wrapping ErrVertexOutOfRange.
+func (p *Permutation) NewIDs(old []int) ([]int, error) {
+	ids := make([]int, len(old))
+	for i, v := range old {
+		if v < 0 || v >= len(p.newID) {
+			return nil, &VertexError{Vertex: v, Err: ErrVertexOutOfRange}
+		}
+		ids[i] = p.newID[v]
+	}
+	return ids, nil
+}
+
+// ReorderedGraph is a Graph whose vertices were renumbered for locality. The solvers
+// run on the embedded Graph; Perm translates sources, and Result maps results back
+// onto the original ids.
+type ReorderedGraph[W Weight] struct {
+	*Graph[W]
+	Perm *Permutation
+}
+
+// Result maps a result computed on g.Graph onto the original vertex ids.
+func (g *ReorderedGraph[W]) Result(r *Result[W]) *Result[W] {
+	n := g.Perm.Len()
+	dist := make([]W, n)
+	for v := range dist {
+		dist[g.Perm.oldID[v]] = r.Dist(v)
+	}
+	var pred []Edge[W]
+	if r.pred != nil {
+		pred = make([]Edge[W], n)
+		for v, e := range r.pred {
+			old := g.Perm.oldID[v]
+			pred[old] = Edge[W]{U: -1, V: old}
+			if e.U >= 0 {
+				pred[old] = Edge[W]{U: g.Perm.oldID[e.U], V: g.Perm.oldID[e.V], Weight: e.Weight}
+			}
+		}
+	}
+	return &Result[W]{dist: dist, pred: pred, reverse: r.reverse}
+}
+
+// Reorder returns a copy of g in which vertex order[i] becomes vertex i. The out-edges
+// of every vertex keep their order, and Edges and Adj are laid out as GraphBuilder
+// does. g is validated first.
+func Reorder[W Weight](g *Graph[W], order []int) (*ReorderedGraph[W], error) {
+	if err := ValidateGraph(g); err != nil {
+		return nil, err
+	}
+	if len(order) != g.N {
+		return nil, &ParameterError{Name: "order", Value: len(order), Reason: fmt.Sprintf("must list all %d vertices", g.N)}
+	}
+	p, err := NewPermutation(order)
+	if err != nil {
+		return nil, err
+	}
+	return permute(g, p), nil
+}
+
+func permute[W Weight](g *Graph[W], p *Permutation) *ReorderedGraph[W] {
+	var arcs []Edge[W]
+	for _, old := range p.oldID {
+		for _, e := range g.Adj[old] {
+			arcs = append(arcs, Edge[W]{U: p.newID[e.U], V: p.newID[e.V], Weight: e.Weight})
+		}
+	}
+	out := &Graph[W]{N: g.N, Directed: g.Directed}
+	out.Edges, out.Adj = groupByTail(g.N, arcs)
+	return &ReorderedGraph[W]{Graph: out, Perm: p}
+}
+
+// ReorderBFS numbers the vertices in breadth-first order, ignoring edge directions, so
+// that neighbors receive nearby ids. Each component is started from its smallest
+// unvisited id.
+func ReorderBFS[W Weight](g *Graph[W]) (*ReorderedGraph[W], error) {
+	nb, err := newUndirectedNeighbors(g)
+	if err != nil {
+		return nil, err
+	}
+	order := make([]int, 0, g.N)
+	visited := make([]bool, g.N)
+	for s := 0; s < g.N; s++ {
+		if !visited[s] {
+			order = nb.bfs(s, visited, order, false)
+		}
+	}
+	return Reorder(g, order)
+}
+
+// ReorderRCM numbers the vertices in reverse Cuthill-McKee order, which keeps the
+// ids of adjacent vertices close and so narrows the bandwidth of the adjacency matrix.
+// Edge directions are ignored, and each component starts from a vertex of minimum degree.
+func ReorderRCM[W Weight](g *Graph[W]) (*ReorderedGraph[W], error) {
+	nb, err := newUndirectedNeighbors(g)
+	if err != nil {
+		return nil, err
+	}
+	byDegree := make([]int, g.N)
+	for v := range byDegree {
+		byDegree[v] = v
+	}
+	slices.SortStableFunc(byDegree, func(a, b int) int { return cmp.Compare(nb.degree(a), nb.degree(b)) })
+
+	order := make([]int, 0, g.N)
+	visited := make([]bool, g.N)
+	for _, s := range byDegree {
+		if !visited[s] {
+			order = nb.bfs(s, visited, order, true)
+		}
+	}
+	slices.Reverse(order)
+	return Reorder(g, order)
+}
+
+// ReorderByDegree numbers the vertices by decreasing total degree, so that the hubs
+// that most searches touch share cache lines. Ties keep their original order.
+func ReorderByDegree[W Weight](g *Graph[W]) (*ReorderedGraph[W], error) {
+	nb, err := newUndirectedNeighbors(g)
+	if err != nil {
+		return nil, err
+	}
+	order := make([]int, g.N)
+	for v := range order {
+		order[v] = v
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(nb.degree(b), nb.degree(a)) })
+	return Reorder(g, order)
+}
+
+// Coord is the position of a vertex in the plane, such as a longitude and latitude.
+type Coord struct {
+	X, Y float64
+}
+
+// ReorderHilbert numbers the vertices along a Hilbert curve through their coordinates,
+// which places geographically close vertices at close ids. coords must hold one finite
+// position per vertex.
+func ReorderHilbert[W Weight](g *Graph[W], coords []Coord) (*ReorderedGraph[W], error) {
+	if g != nil && len(coords) != g.N {
+		return nil, &ParameterError{Name: "coords", Value: len(coords), Reason: "must hold one position per vertex"}
+	}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for v, c := range coords {
+		if math.IsNaN(c.X) || math.IsNaN(c.Y) || math.IsInf(c.X, 0) || math.IsInf(c.Y, 0) {
+			return nil, &VertexError{Vertex: v, Err: fmt.Errorf("%w: coordinate (%v, %v)", ErrInvalidParameter, c.X, c.Y)}
+		}
+		minX, maxX = min(minX, c.X), max(maxX, c.X)
+		minY, maxY = min(minY, c.Y), max(maxY, c.Y)
+	}
+
+	// Map the bounding box onto a 2^16 x 2^16 grid; a square cell keeps the aspect ratio.
+	const side = 1 << 16
+	scale := (side - 1) / max(maxX-minX, maxY-minY, math.SmallestNonzeroFloat64)
+	key := make([]uint64, len(coords))
+	for v, c := range coords {
+		key[v] = hilbertIndex(side, uint32((c.X-minX)*scale), uint32((c.Y-minY)*scale))
+	}
+	order := make([]int, len(coords))
+	for v := range order {
+		order[v] = v
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(key[a], key[b]) })
+	return Reorder(g, order)
+}
+
+// hilbertIndex returns the position of (x, y) along the Hilbert curve that fills a
+// side x side grid, side being a power of two.
+func hilbertIndex(side, x, y uint32) uint64 {
+	var d uint64
+	for s := side / 2; s > 0; s /= 2 {
+		var rx, ry uint32
+		if x&s != 0 {
+			rx = 1
+		}
+		if y&s != 0 {
+			ry = 1
+		}
+		d += uint64(s) * uint64(s) * uint64((3*rx)^ry)
+		// Rotate the quadrant so the curve inside it has the canonical orientation.
+		if ry == 0 {
+			if rx == 1 {
+				x, y = side-1-x, side-1-y
+			}
+			x, y = y, x
+		}
+	}
+	return d
+}
+
+// undirectedNeighbors lists the out- and in-neighbors of every vertex of a validated graph.
+type undirectedNeighbors[W Weight] struct {
+	out, in *CSRGraph[W]
+}
+
+func newUndirectedNeighbors[W Weight](g *Graph[W]) (*undirectedNeighbors[W], error) {
+	out, err := NewCSRGraph(g)
+	if err != nil {
+		return nil, err
+	}
+	return &undirectedNeighbors[W]{out: out, in: out.Transpose()}, nil
+}
+
+func (nb *undirectedNeighbors[W]) degree(v int) int {
+	return nb.out.Degree(v) + nb.in.Degree(v)
+}
+
+// bfs appends the vertices reached from s in breadth-first order to order. If
+// byDegree is set, the unvisited neighbors of each vertex are enqueued by increasing
+// degree, as Cuthill-McKee requires; otherwise in adjacency order.
+func (nb *undirectedNeighbors[W]) bfs(s int, visited []bool, order []int, byDegree bool) []int {
+	head := len(order)
+	visited[s] = true
+	order = append(order, s)
+	for ; head < len(order); head++ {
+		u := order[head]
+		first := len(order)
+		for _, g := range [2]*CSRGraph[W]{nb.out, nb.in} {
+			targets, _ := g.Neighbors(u)
+			for _, v := range targets {
+				if !visited[v] {
+					visited[v] = true
+					order = append(order, int(v))
+				}
+			}
+		}
+		if byDegree {
+			slices.SortStableFunc(order[first:], func(a, b int) int { return cmp.Compare(nb.degree(a), nb.degree(b)) })
+		}
+	}
+	return order
+}
@@ -0,0 +1,88 @@
+package common_test
+
+import (
+	"errors"
+	"math/rand"
+	"playground/bmssp"
+	"playground/common"
+	"playground/internal/graphtest"
+	"slices"
+	"testing"
+)
+
+func TestReorder(t *testing.T) {
+	const rows, cols = 20, 20
+	// A grid whose vertex ids have been shuffled, with coordinates for the Hilbert order.
+	r := rand.New(rand.NewSource(11))
+	id := r.Perm(rows * cols)
+	coords := make([]common.Coord, rows*cols)
+	b := common.NewGraphBuilder[float64](false).WithVertices(rows * cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			coords[id[i*cols+j]] = common.Coord{X: float64(j), Y: float64(i)}
+			if j < cols-1 {
+				b.AddEdge(id[i*cols+j], id[i*cols+j+1], float64(r.Intn(9)+1))
+			}
+			if i < rows-1 {
+				b.AddEdge(id[i*cols+j], id[(i+1)*cols+j], float64(r.Intn(9)+1))
+			}
+		}
+	}
+	g := graphtest.MustBuild(b)
+	want, err := bmssp.SolveSSSP(g, []int{id[0]})
+	if err != nil {
+		t.Fatalf("SolveSSSP() returned an error: %v", err)
+	}
+
+	orderings := map[string]func(*common.Graph[float64]) (*common.ReorderedGraph[float64], error){
+		"BFS":    common.ReorderBFS[float64],
+		"RCM":    common.ReorderRCM[float64],
+		"degree": common.ReorderByDegree[float64],
+		"Hilbert": func(g *common.Graph[float64]) (*common.ReorderedGraph[float64], error) {
+			return common.ReorderHilbert(g, coords)
+		},
+	}
+	for name, reorder := range orderings {
+		rg, err := reorder(g)
+		if err != nil {
+			t.Fatalf("%s: reordering returned an error: %v", name, err)
+		}
+		// Level-by-level orders keep every edge within two rows of the grid.
+		if (name == "BFS" || name == "RCM") && bandwidth(rg.Graph) > 2*cols {
+			t.Errorf("%s: expected a bandwidth of at most %d, got %d", name, 2*cols, bandwidth(rg.Graph))
+		}
+		sources, err := rg.Perm.NewIDs([]int{id[0]})
+		if err != nil {
+			t.Fatalf("%s: NewIDs() returned an error: %v", name, err)
+		}
+		res, err := bmssp.SolveSSSP(rg.Graph, sources)
+		if err != nil {
+			t.Fatalf("%s: SolveSSSP() returned an error: %v", name, err)
+		}
+		got := rg.Result(res.Result)
+		if !slices.Equal(got.Distances(), want.Distances()) {
+			t.Fatalf("%s: distances mapped back to the original ids differ", name)
+		}
+		if vertices, _, ok := got.Path(id[len(id)-1]); !ok || vertices[0] != id[0] {
+			t.Errorf("%s: expected a path from the source in original ids, got %v", name, vertices)
+		}
+	}
+
+	if _, err := common.Reorder(g, []int{0, 0}); err == nil {
+		t.Error("expected Reorder to reject an order that does not list every vertex")
+	}
+	if _, err := common.NewPermutation([]int{1, 1}); !errors.Is(err, common.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for a repeated vertex, got %v", err)
+	}
+}
+
+// --- Helper Functions ---
+
+// bandwidth returns the largest id difference between the endpoints of an edge.
+func bandwidth(g *common.Graph[float64]) int {
+	w := 0
+	for _, e := range g.Edges {
+		w = max(w, e.U-e.V, e.V-e.U)
+	}
+	return w
+}
@@ -0,0 +1,146 @@
+package common
+
+import (
+	"iter"
+	"slices"
+)
+
+// Result holds the distances computed by a solver for the dense vertex ids 0..N-1,
+// and optionally the edge through which each vertex was reached.
+type Result[W Weight] struct {
+	dist []W
+	pred []Edge[W] // nil if the solver does not track predecessors; U < 0 marks no predecessor.
+
+	// Set for searches that ran on the transpose of the graph. pred then holds the
+	// edges of the transpose, which Pred and Path turn around.
+	reverse bool
+}
+
+// NewResult wraps dist, where unreachable vertices hold Inf[W](), and pred, which may be
+// nil. A pred entry with a negative U marks a vertex without a predecessor. The
+// Result takes ownership of both slices.
+func NewResult[W Weight](dist []W, pred []Edge[W]) *Result[W] {
+	return &Result[W]{dist: dist, pred: pred}
+}
+
+// NewReverseResult is like NewResult for a search that ran on the transpose of a graph
+// from a set of destinations. dist[v] is then the distance from v to the nearest
+// destination, and pred holds the predecessor edges found on the transpose.
+func NewReverseResult[W Weight](dist []W, pred []Edge[W]) *Result[W] {
+	return &Result[W]{dist: dist, pred: pred, reverse: true}
+}
+
+// Reverse reports whether the result belongs to a search toward a set of destinations.
+func (r *Result[W]) Reverse() bool {
+	return r.reverse
+}
+
+// Len returns the number of vertices covered by the result.
+func (r *Result[W]) Len() int {
+	return len(r.dist)
+}
+
+// Dist returns the distance of v, Inf[W]() if v is unreachable or out of range.
+func (r *Result[W]) Dist(v int) W {
+	if v < 0 || v >= len(r.dist) {
+		return Inf[W]()
+	}
+	return r.dist[v]
+}
+
+// Reachable reports whether v has a finite distance.
+func (r *Result[W]) Reachable(v int) bool {
+	return !IsInf(r.Dist(v))
+}
+
+// HasPredecessors reports whether the solver recorded predecessor edges.
+func (r *Result[W]) HasPredecessors() bool {
+	return r.pred != nil
+}
+
+// Pred returns the edge through which v was reached. For a reverse result it returns
+// the first edge of the path from v toward the destinations instead, oriented as in
+// the original graph, so e.U is v. ok is false for sources, unreachable vertices and
+// results without predecessors.
+func (r *Result[W]) Pred(v int) (e Edge[W], ok bool) {
+	if r.pred == nil || v < 0 || v >= len(r.pred) || r.pred[v].U < 0 {
+		return Edge[W]{}, false
+	}
+	e = r.pred[v]
+	if r.reverse {
+		e.U, e.V = e.V, e.U
+	}
+	return e, true
+}
+
+// Path follows the predecessor edges back from target and returns the vertices and
+// edges of the path in source-to-target order. For a reverse result the path leads
+// from target to its nearest destination. ok is false if target is unreachable, the
+// result has no predecessors, or the predecessor edges contain a cycle.
+func (r *Result[W]) Path(target int) (vertices []int, edges []Edge[W], ok bool) {
+	if r.pred == nil || !r.Reachable(target) {
+		return nil, nil, false
+	}
+
+	vertices = []int{target}
+	for v := target; ; {
+		e, has := r.Pred(v)
+		if !has {
+			break
+		}
+		// A well-formed tree has at most N-1 edges on any root path.
+		if len(edges) >= len(r.dist) {
+			return nil, nil, false
+		}
+		edges = append(edges, e)
+		if r.reverse {
+			v = e.V
+		} else {
+			v = e.U
+		}
+		vertices = append(vertices, v)
+	}
+
+	if !r.reverse {
+		slices.Reverse(vertices)
+		slices.Reverse(edges)
+	}
+	return vertices, edges, true
+}
+
+// All iterates over every vertex and its distance, including unreachable ones.
+func (r *Result[W]) All() iter.Seq2[int, W] {
+	return func(yield func(int, W) bool) {
+		for v, d := range r.dist {
+			if !yield(v, d) {
+				return
+			}
+		}
+	}
+}
+
+// Reached iterates over the reachable vertices and their distances.
+func (r *Result[W]) Reached() iter.Seq2[int, W] {
+	return func(yield func(int, W) bool) {
+		for v, d := range r.dist {
+			if !IsInf(d) && !yield(v, d) {
+				return
+			}
+		}
+	}
+}
+
+// Distances returns the underlying distance slice. It must not be modified.
+func (r *Result[W]) Distances() []W {
+	return r.dist
+}
+
+// Map returns the distances as a map keyed by vertex, the representation solvers
+// returned before Result existed.
+func (r *Result[W]) Map() map[int]W {
+	m := make(map[int]W, len(r.dist))
+	for v, d := range r.dist {
+		m[v] = d
+	}
+	return m
+}
@@ -1,6 +1,11 @@
 package common
 
-type ShortestPathSolver interface {
-	// Solve executes the algorithm and returns the final distance map.
-	Solve() (map[int]float64, error)
+import "context"
+
+type ShortestPathSolver[W Weight] interface {
+	// Solve executes the algorithm and returns the final distances.
+	Solve() (*Result[W], error)
+	// SolveContext is like Solve but stops once ctx is done, returning ctx.Err()
+	// together with the distances that were already settled.
+	SolveContext(ctx context.Context) (*Result[W], error)
 }
@@ -0,0 +1,65 @@
+package common
+
+// StampSet is a set of vertices in [0, n) that can be emptied in O(1). A vertex is a
+// member while its mark equals the current epoch, so Reset only bumps the epoch.
+// The zero value must be Reset before use.
+type StampSet struct {
+	mark  []uint32
+	epoch uint32
+}
+
+// Reset empties the set and makes room for vertices in [0, n).
+func (s *StampSet) Reset(n int) {
+	if len(s.mark) < n {
+		s.mark = make([]uint32, n)
+		s.epoch = 0
+	}
+	s.epoch++
+	if s.epoch == 0 { // Wrapped around: old marks could collide with the new epoch.
+		clear(s.mark)
+		s.epoch = 1
+	}
+}
+
+// Add inserts v and reports whether it was not already a member.
+func (s *StampSet) Add(v int) bool {
+	if s.mark[v] == s.epoch {
+		return false
+	}
+	s.mark[v] = s.epoch
+	return true
+}
+
+// Has reports whether v is a member.
+func (s *StampSet) Has(v int) bool {
+	return s.mark[v] == s.epoch
+}
+
+// Remove deletes v from the set.
+func (s *StampSet) Remove(v int) {
+	s.mark[v] = 0
+}
+
+// EntryPool hands out DistEntry values that are recycled by Reset, so a priority
+// queue that is rebuilt many times stops allocating once the pool has grown.
+type EntryPool[W Weight] struct {
+	entries []*DistEntry[W]
+	used    int
+}
+
+// Get returns an entry for v with the given distance.
+func (p *EntryPool[W]) Get(v int, dist W) *DistEntry[W] {
+	if p.used == len(p.entries) {
+		p.entries = append(p.entries, &DistEntry[W]{})
+	}
+	e := p.entries[p.used]
+	p.used++
+	*e = DistEntry[W]{Vertex: v, Dist: dist}
+	return e
+}
+
+// Reset makes every entry handed out so far available again. Entries must no longer
+// be referenced by the caller.
+func (p *EntryPool[W]) Reset() {
+	p.used = 0
+}
@@ -0,0 +1,197 @@
+package common
+
+import "iter"
+
+// View is a zero-copy, read-only view of a Graph restricted to the vertices and edges
+// its filters accept. It reads the parent's adjacency lists on every call, so creating
+// one costs nothing and later changes to the parent show through. Vertex ids are those
+// of the parent; Subgraph compacts a view into a Graph the solvers can run on.
+type View[W Weight] struct {
+	g      *Graph[W]
+	vertex func(v int) bool     // nil accepts every vertex.
+	edge   func(e Edge[W]) bool // nil accepts every edge.
+}
+
+// NewView returns a view of g that accepts every vertex and edge.
+func NewView[W Weight](g *Graph[W]) *View[W] {
+	return &View[W]{g: g}
+}
+
+// FilterEdges returns a view of g that only keeps the edges accepted by keep, such as
+// every edge that is not a toll road.
+func FilterEdges[W Weight](g *Graph[W], keep func(e Edge[W]) bool) *View[W] {
+	return NewView(g).WithEdges(keep)
+}
+
+// WithVertices returns a view that additionally drops every vertex rejected by keep,
+// together with its edges. Result.Reachable restricts a view to the vertices a bounded
+// search reached.
+func (v *View[W]) WithVertices(keep func(v int) bool) *View[W] {
+	w := *v
+	if prev := v.vertex; prev != nil {
+		w.vertex = func(u int) bool { return prev(u) && keep(u) }
+	} else {
+		w.vertex = keep
+	}
+	return &w
+}
+
+// WithEdges returns a view that additionally drops every edge rejected by keep.
+func (v *View[W]) WithEdges(keep func(e Edge[W]) bool) *View[W] {
+	w := *v
+	if prev := v.edge; prev != nil {
+		w.edge = func(e Edge[W]) bool { return prev(e) && keep(e) }
+	} else {
+		w.edge = keep
+	}
+	return &w
+}
+
+// NumVertices returns the N of the parent graph, since a view keeps its ids.
+func (v *View[W]) NumVertices() int {
+	return v.g.N
+}
+
+// HasVertex reports whether u lies within [0, N) and is accepted by the view.
+func (v *View[W]) HasVertex(u int) bool {
+	return u >= 0 && u < v.g.N && (v.vertex == nil || v.vertex(u))
+}
+
+// Vertices iterates over the accepted vertices in increasing order.
+func (v *View[W]) Vertices() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for u := 0; u < v.g.N; u++ {
+			if v.HasVertex(u) && !yield(u) {
+				return
+			}
+		}
+	}
+}
+
+// OutEdges iterates over the accepted out-edges of u whose head is also accepted.
+func (v *View[W]) OutEdges(u int) iter.Seq[Edge[W]] {
+	return func(yield func(Edge[W]) bool) {
+		if !v.HasVertex(u) {
+			return
+		}
+		for _, e := range v.g.Adj[u] {
+			if v.HasVertex(e.V) && (v.edge == nil || v.edge(e)) && !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// Subgraph copies the view into a Subgraph whose vertices are the accepted vertices
+// in increasing order of their parent id.
+func (v *View[W]) Subgraph() *Subgraph[W] {
+	var vertices []int
+	for u := range v.Vertices() {
+		vertices = append(vertices, u)
+	}
+	return v.extract(vertices)
+}
+
+// extract builds the Subgraph over vertices, which must be distinct and accepted.
+func (v *View[W]) extract(vertices []int) *Subgraph[W] {
+	s := &Subgraph[W]{toParent: vertices, fromParent: make([]int, v.g.N)}
+	for p := range s.fromParent {
+		s.fromParent[p] = -1
+	}
+	for i, p := range vertices {
+		s.fromParent[p] = i
+	}
+
+	var arcs []Edge[W]
+	for i, p := range vertices {
+		for e := range v.OutEdges(p) {
+			arcs = append(arcs, Edge[W]{U: i, V: s.fromParent[e.V], Weight: e.Weight})
+		}
+	}
+	s.Graph = &Graph[W]{N: len(vertices), Directed: v.g.Directed}
+	s.Edges, s.Adj = groupByTail(len(vertices), arcs)
+	return s
+}
+
+// InducedSubgraph returns the subgraph of g induced by vertices, with vertices[i]
+// becoming vertex i. Repeated vertices keep their first id. A vertex outside [0, N)
+// is reported as a VertexError wrapping ErrVertexOutOfRange.
+func InducedSubgraph[W Weight](g *Graph[W], vertices []int) (*Subgraph[W], error) {
+	seen := make([]bool, max(g.N, 0))
+	distinct := make([]int, 0, len(vertices))
+	for _, p := range vertices {
+		if p < 0 || p >= g.N {
+			return nil, &VertexError{Vertex: p, Err: ErrVertexOutOfRange}
+		}
+		if !seen[p] {
+			seen[p] = true
+			distinct = append(distinct, p)
+		}
+	}
+	return NewView(g).extract(distinct), nil
+}
+
+// Subgraph is a Graph cut out of a parent graph, with tables that translate vertex
+// ids in both directions. The solvers run on the embedded Graph; Result maps their
+// results back onto the parent's ids.
+type Subgraph[W Weight] struct {
+	*Graph[W]
+	toParent   []int // Parent id of every subgraph vertex.
+	fromParent []int // Subgraph id of every parent vertex, -1 if it was dropped.
+}
+
+// ParentID returns the parent id of the subgraph vertex v, or -1 if v is out of range.
+func (s *Subgraph[W]) ParentID(v int) int {
+	if v < 0 || v >= len(s.toParent) {
+		return -1
+	}
+	return s.toParent[v]
+}
+
+// LocalID returns the subgraph id of the parent vertex p and whether p was kept.
+func (s *Subgraph[W]) LocalID(p int) (int, bool) {
+	if p < 0 || p >= len(s.fromParent) || s.fromParent[p] < 0 {
+		return -1, false
+	}
+	return s.fromParent[p], true
+}
+
+// LocalIDs translates parent ids, such as sources, into subgraph ids. A vertex that
+// is not part of the subgraph is reported as a VertexError wrapping ErrNotInSubgraph.
+func (s *Subgraph[W]) LocalIDs(parent []int) ([]int, error) {
+	ids := make([]int, len(parent))
+	for i, p := range parent {
+		id, ok := s.LocalID(p)
+		if !ok {
+			return nil, &VertexError{Vertex: p, Err: ErrNotInSubgraph}
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// Result maps a result computed on s.Graph onto the ids of the parent graph. Dropped
+// vertices are unreachable, and predecessor edges are translated along with their
+// endpoints.
+func (s *Subgraph[W]) Result(r *Result[W]) *Result[W] {
+	n := len(s.fromParent)
+	dist := make([]W, n)
+	for p := range dist {
+		dist[p] = Inf[W]()
+	}
+	var pred []Edge[W]
+	if r.pred != nil {
+		pred = make([]Edge[W], n)
+		for p := range pred {
+			pred[p] = Edge[W]{U: -1, V: p}
+		}
+	}
+	for v, p := range s.toParent {
+		dist[p] = r.Dist(v)
+		if pred != nil && r.pred[v].U >= 0 {
+			e := r.pred[v]
+			pred[p] = Edge[W]{U: s.toParent[e.U], V: s.toParent[e.V], Weight: e.Weight}
+		}
+	}
+	return &Result[W]{dist: dist, pred: pred, reverse: r.reverse}
+}
@@ -0,0 +1,90 @@
+package common_test
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"playground/bmssp"
+	"playground/common"
+	"playground/dijkstra"
+	"playground/internal/graphtest"
+	"slices"
+	"testing"
+)
+
+func TestSubgraph(t *testing.T) {
+	g := graphtest.Random[float64](300, 1200, 7)
+	r := rand.New(rand.NewSource(7))
+	region := r.Perm(g.N)[:200]
+	inRegion := make([]bool, g.N)
+	for _, v := range region {
+		inRegion[v] = true
+	}
+	noToll := func(e common.Edge[float64]) bool { return e.Weight <= 7 }
+
+	// Reference graph on the parent ids with the same vertices and edges removed.
+	b := common.NewGraphBuilder[float64](true).WithVertices(g.N)
+	for _, e := range g.Edges {
+		if inRegion[e.U] && inRegion[e.V] && noToll(e) {
+			b.AddEdge(e.U, e.V, e.Weight)
+		}
+	}
+	src := region[0]
+	want, err := dijkstra.NewDijkstraAlgorithm(graphtest.MustBuild(b), []int{src}, nil).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+
+	induced, err := common.InducedSubgraph(g, region)
+	if err != nil {
+		t.Fatalf("InducedSubgraph() returned an error: %v", err)
+	}
+	subgraphs := map[string]*common.Subgraph[float64]{
+		"view":           common.FilterEdges(g, noToll).WithVertices(func(v int) bool { return inRegion[v] }).Subgraph(),
+		"induced+filter": common.NewView(induced.Graph).WithEdges(noToll).Subgraph(),
+	}
+	for name, sub := range subgraphs {
+		if sub.N != len(region) {
+			t.Fatalf("%s: expected %d vertices, got %d", name, len(region), sub.N)
+		}
+		sources := []int{src}
+		if name == "induced+filter" {
+			// The second subgraph is cut out of the first, so ids are translated twice.
+			sources, _ = induced.LocalIDs(sources)
+		}
+		sources, err := sub.LocalIDs(sources)
+		if err != nil {
+			t.Fatalf("%s: LocalIDs() returned an error: %v", name, err)
+		}
+		local, err := bmssp.NewBMSSPAlgorithm(sub.Graph, 3, math.Inf(1), sources).Solve()
+		if err != nil {
+			t.Fatalf("%s: Solve() returned an error: %v", name, err)
+		}
+		got := sub.Result(local)
+		if name == "induced+filter" {
+			got = induced.Result(got)
+		}
+		if !slices.Equal(got.Distances(), want.Distances()) {
+			t.Fatalf("%s: distances mapped back to parent ids differ from the reference", name)
+		}
+		for v := range got.Reached() {
+			vertices, edges, ok := got.Path(v)
+			if !ok || vertices[0] != src {
+				t.Fatalf("%s: expected a path from %d to %d, got %v (ok=%v)", name, src, v, vertices, ok)
+			}
+			for _, e := range edges {
+				if !inRegion[e.U] || !inRegion[e.V] || !slices.Contains(g.Adj[e.U], e) {
+					t.Fatalf("%s: path edge %v is not a kept edge of g", name, e)
+				}
+			}
+		}
+	}
+
+	outside := slices.IndexFunc(inRegion, func(in bool) bool { return !in })
+	if _, err := induced.LocalIDs([]int{outside}); !errors.Is(err, common.ErrNotInSubgraph) {
+		t.Errorf("expected ErrNotInSubgraph, got %v", err)
+	}
+	if _, err := common.InducedSubgraph(g, []int{g.N}); !errors.Is(err, common.ErrVertexOutOfRange) {
+		t.Errorf("expected ErrVertexOutOfRange, got %v", err)
+	}
+}
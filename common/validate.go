@@ -0,0 +1,218 @@
+package common
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	// ErrNegativeWeight is reported for edges with a weight below zero.
+	ErrNegativeWeight = errors.New("negative edge weight")
+	// ErrInvalidWeight is reported for edges whose weight is NaN or at least Inf.
+	ErrInvalidWeight = errors.New("edge weight is NaN or infinite")
+	// ErrVertexOutOfRange is reported for edges or adjacency keys outside [0, N).
+	ErrVertexOutOfRange = errors.New("vertex out of range")
+	// ErrInconsistentGraph is reported when Adj and Edges disagree.
+	ErrInconsistentGraph = errors.New("adjacency list disagrees with edge list")
+	// ErrDuplicateEdge is reported by GraphBuilder for parallel edges it was told to reject.
+	ErrDuplicateEdge = errors.New("duplicate edge")
+	// ErrUnknownLabel is reported for vertex labels that a LabeledGraph does not contain.
+	ErrUnknownLabel = errors.New("unknown vertex label")
+	// ErrNotInSubgraph is reported for parent vertices that a Subgraph does not contain.
+	ErrNotInSubgraph = errors.New("vertex not in subgraph")
+	// ErrNoSources is reported when a solver is given no source vertices.
+	ErrNoSources = errors.New("at least one source vertex must be provided")
+	// ErrSourceOutOfRange is reported for source vertices outside [0, N).
+	ErrSourceOutOfRange = errors.New("source vertex out of range")
+	// ErrTargetOutOfRange is reported for target vertices outside [0, N).
+	ErrTargetOutOfRange = errors.New("target vertex out of range")
+	// ErrInvalidParameter is reported for solver parameters outside their valid range.
+	ErrInvalidParameter = errors.New("invalid parameter")
+)
+
+// EdgeError reports a problem with a single edge.
+type EdgeError[W Weight] struct {
+	Edge Edge[W]
+	Err  error
+}
+
+func (e *EdgeError[W]) Error() string {
+	return fmt.Sprintf("edge %d->%d (weight %v): %v", e.Edge.U, e.Edge.V, e.Edge.Weight, e.Err)
+}
+
+func (e *EdgeError[W]) Unwrap() error { return e.Err }
+
+// VertexError reports a problem with a single vertex.
+type VertexError struct {
+	Vertex int
+	Err    error
+}
+
+func (e *VertexError) Error() string {
+	return fmt.Sprintf("vertex %d: %v", e.Vertex, e.Err)
+}
+
+func (e *VertexError) Unwrap() error { return e.Err }
+
+// ParameterError reports a solver parameter outside its valid range. It unwraps to
+// ErrInvalidParameter; Reason describes the constraint that was violated.
+type ParameterError struct {
+	Name   string
+	Value  any
+	Reason string
+}
+
+func (e *ParameterError) Error() string {
+	return fmt.Sprintf("%v: %s = %v: %s", ErrInvalidParameter, e.Name, e.Value, e.Reason)
+}
+
+func (e *ParameterError) Unwrap() error { return ErrInvalidParameter }
+
+// ValidateGraph checks that every adjacency entry lies within [0, N), is stored under
+// its tail vertex and has a finite, non-negative weight, and that every entry of Edges
+// also appears in Adj. Adj may hold edges that Edges does not, such as the reverse
+// edges of an undirected graph. Vertices are checked in order, so a graph with several
+// problems always reports the same one.
+func ValidateGraph[W Weight](g *Graph[W]) error {
+	if g == nil {
+		return &ParameterError{Name: "graph", Value: nil, Reason: "must not be nil"}
+	}
+	if g.N < 0 {
+		return &ParameterError{Name: "N", Value: g.N, Reason: "must be non-negative"}
+	}
+
+	// Report the smallest stray key, so that the error does not depend on map order.
+	stray, hasStray := 0, false
+	for u, adj := range g.Adj {
+		if len(adj) > 0 && (u < 0 || u >= g.N) && (!hasStray || u < stray) {
+			stray, hasStray = u, true
+		}
+	}
+	if hasStray {
+		return &VertexError{Vertex: stray, Err: ErrVertexOutOfRange}
+	}
+
+	adj := make([][]Edge[W], g.N)
+	for u := range adj {
+		adj[u] = g.Adj[u]
+		for _, e := range adj[u] {
+			if err := validateEdge(g, e); err != nil {
+				return err
+			}
+			if e.U != u {
+				return &EdgeError[W]{Edge: e, Err: ErrInconsistentGraph}
+			}
+		}
+	}
+	for _, e := range g.Edges {
+		if err := validateEdge(g, e); err != nil {
+			return err
+		}
+	}
+	if e, ok := missingEdge(g.Edges, adj); ok {
+		return &EdgeError[W]{Edge: e, Err: ErrInconsistentGraph}
+	}
+	return nil
+}
+
+// missingEdge returns an entry of edges that adj does not hold, comparing both as
+// multisets. Edges usually lists the edges of each tail in the order of its adjacency
+// list, which one cursor per vertex confirms without allocating per edge; otherwise
+// both sides are sorted and merged.
+func missingEdge[W Weight](edges []Edge[W], adj [][]Edge[W]) (Edge[W], bool) {
+	next := make([]int, len(adj))
+	inOrder := true
+	for _, e := range edges {
+		list, i := adj[e.U], next[e.U]
+		for i < len(list) && list[i] != e {
+			i++
+		}
+		if i == len(list) {
+			inOrder = false
+			break
+		}
+		next[e.U] = i + 1
+	}
+	if inOrder {
+		return Edge[W]{}, false
+	}
+
+	want := slices.SortedFunc(slices.Values(edges), compareEdges[W])
+	var have []Edge[W]
+	for _, list := range adj {
+		have = append(have, list...)
+	}
+	slices.SortFunc(have, compareEdges[W])
+	j := 0
+	for _, e := range want {
+		for j < len(have) && compareEdges(have[j], e) < 0 {
+			j++
+		}
+		if j == len(have) || have[j] != e {
+			return e, true
+		}
+		j++
+	}
+	return Edge[W]{}, false
+}
+
+// compareEdges orders edges by tail, head and weight.
+func compareEdges[W Weight](a, b Edge[W]) int {
+	if c := cmp.Compare(a.U, b.U); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.V, b.V); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Weight, b.Weight)
+}
+
+func validateEdge[W Weight](g *Graph[W], e Edge[W]) error {
+	switch {
+	case e.U < 0 || e.U >= g.N || e.V < 0 || e.V >= g.N:
+		return &EdgeError[W]{Edge: e, Err: ErrVertexOutOfRange}
+	}
+	if err := CheckWeight(e.Weight); err != nil {
+		return &EdgeError[W]{Edge: e, Err: err}
+	}
+	return nil
+}
+
+// CheckWeight returns ErrInvalidWeight if w is NaN or infinite, ErrNegativeWeight if
+// it is below zero, and nil otherwise. Readers use it to reject a weight on the line
+// it appears on, with the same errors as Build.
+func CheckWeight[W Weight](w W) error {
+	switch {
+	case w != w || IsInf(w): // NaN is the only value unequal to itself.
+		return ErrInvalidWeight
+	case w < 0:
+		return ErrNegativeWeight
+	}
+	return nil
+}
+
+// ValidateSources checks that sources is non-empty and every source lies within [0, N).
+func ValidateSources(g VertexCounter, sources []int) error {
+	if len(sources) == 0 {
+		return ErrNoSources
+	}
+	for _, s := range sources {
+		if s < 0 || s >= g.NumVertices() {
+			return &VertexError{Vertex: s, Err: ErrSourceOutOfRange}
+		}
+	}
+	return nil
+}
+
+// ValidateTargets checks that every target lies within [0, N). An empty target set
+// is valid and means that no early termination was requested.
+func ValidateTargets(g VertexCounter, targets []int) error {
+	for _, t := range targets {
+		if t < 0 || t >= g.NumVertices() {
+			return &VertexError{Vertex: t, Err: ErrTargetOutOfRange}
+		}
+	}
+	return nil
+}
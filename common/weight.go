@@ -0,0 +1,94 @@
+package common
+
+import (
+	"math"
+	"strconv"
+	"unsafe"
+)
+
+// Weight is the set of edge weight types supported by graphs and solvers. Integer
+// weights compare exactly, so equal path lengths always form a tie.
+type Weight interface {
+	~int32 | ~int64 | ~uint32 | ~float32 | ~float64
+}
+
+// IsFloat reports whether W is a floating-point type.
+func IsFloat[W Weight]() bool {
+	var zero W
+	return (zero+1)/2 != 0 // Only floating-point division keeps the fraction.
+}
+
+// IsUnsigned reports whether W is an unsigned integer type.
+func IsUnsigned[W Weight]() bool {
+	var zero W
+	return zero-1 > 0 // Unsigned types wrap around to their maximum.
+}
+
+// WeightBits returns the size of W in bits.
+func WeightBits[W Weight]() int {
+	var zero W
+	return int(unsafe.Sizeof(zero)) * 8
+}
+
+// Inf returns the distance that marks an unreachable vertex: +Inf for floating-point
+// types and the largest representable value for integer types.
+func Inf[W Weight]() W {
+	var zero W
+	switch {
+	case IsFloat[W]():
+		return W(math.Inf(1))
+	case IsUnsigned[W]():
+		return zero - 1
+	case WeightBits[W]() == 32:
+		m := int64(math.MaxInt32)
+		return W(m)
+	default:
+		m := int64(math.MaxInt64)
+		return W(m)
+	}
+}
+
+// IsInf reports whether d is the distance of an unreachable vertex.
+func IsInf[W Weight](d W) bool {
+	return d >= Inf[W]()
+}
+
+// AddWeights returns a + b for non-negative a and b. A sum that does not fit into W
+// saturates at Inf instead of wrapping around.
+func AddWeights[W Weight](a, b W) W {
+	if s := a + b; s >= a {
+		return s
+	}
+	return Inf[W]()
+}
+
+// ParseWeight parses a decimal number into W. Values that W cannot hold, such as
+// fractions for integer types or out-of-range numbers, are reported as a
+// *strconv.NumError.
+func ParseWeight[W Weight](s string) (W, error) {
+	bits := WeightBits[W]()
+	switch {
+	case IsFloat[W]():
+		f, err := strconv.ParseFloat(s, bits)
+		return W(f), err
+	case IsUnsigned[W]():
+		u, err := strconv.ParseUint(s, 10, bits)
+		return W(u), err
+	default:
+		i, err := strconv.ParseInt(s, 10, bits)
+		return W(i), err
+	}
+}
+
+// AppendWeight appends the shortest decimal form of w that ParseWeight reads back
+// exactly.
+func AppendWeight[W Weight](buf []byte, w W) []byte {
+	switch {
+	case IsFloat[W]():
+		return strconv.AppendFloat(buf, float64(w), 'g', -1, WeightBits[W]())
+	case IsUnsigned[W]():
+		return strconv.AppendUint(buf, uint64(w), 10)
+	default:
+		return strconv.AppendInt(buf, int64(w), 10)
+	}
+}
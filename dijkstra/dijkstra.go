@@ -1,74 +1,116 @@
 package dijkstra
 
 import (
-	"container/heap"
-	"errors"
-	"math"
+	"context"
+	"fmt"
 	"playground/common"
 )
 
 // DijkstraAlgorithm encapsulates the state for a run of Dijkstra's algorithm.
-type DijkstraAlgorithm struct {
-	graph    *common.Graph
+type DijkstraAlgorithm[W common.Weight] struct {
+	graph    *common.Graph[W]    // Validated and converted on the first Solve, then dropped.
+	csr      *common.CSRGraph[W] // Graph the search runs on.
 	sources  []int
-	boundary *float64 // A nil boundary means the search is unbounded.
+	boundary *W    // A nil boundary means the search is unbounded.
+	targets  []int // If non-empty, the search stops once all of them are settled.
+	reverse  bool  // If set, csr is the transpose and sources act as destinations.
 }
 
-// NewDijkstraAlgorithm creates a new solver for Dijkstra's algorithm.
-func NewDijkstraAlgorithm(g *common.Graph, sources []int, boundary *float64) *DijkstraAlgorithm {
-	return &DijkstraAlgorithm{
+// NewDijkstraAlgorithm creates a new solver for Dijkstra's algorithm. g is validated
+// and converted into a CSRGraph on the first Solve; later Solves reuse the conversion
+// and do not see changes to g.
+func NewDijkstraAlgorithm[W common.Weight](g *common.Graph[W], sources []int, boundary *W) *DijkstraAlgorithm[W] {
+	return &DijkstraAlgorithm[W]{
 		graph:    g,
 		sources:  sources,
 		boundary: boundary,
 	}
 }
 
-// Solve executes Dijkstra's algorithm based on the configured sources and boundary.
-func (a *DijkstraAlgorithm) Solve() (map[int]float64, error) {
-	if len(a.sources) == 0 {
-		return nil, errors.New("dijkstra: at least one source vertex must be provided")
-	}
-
-	dist := make(map[int]float64)
-	for i := 0; i < a.graph.N; i++ {
-		dist[i] = math.Inf(1)
+// NewDijkstraAlgorithmCSR is like NewDijkstraAlgorithm but runs on a CSRGraph, which
+// skips the validation and conversion of a Graph.
+func NewDijkstraAlgorithmCSR[W common.Weight](g *common.CSRGraph[W], sources []int, boundary *W) *DijkstraAlgorithm[W] {
+	return &DijkstraAlgorithm[W]{
+		csr:      g,
+		sources:  sources,
+		boundary: boundary,
 	}
+}
 
-	pq := make(common.PriorityQueue, 0)
-	heap.Init(&pq)
+// WithTargets makes Solve stop as soon as every target is settled. The result then
+// holds exact distances for the targets and for the vertices settled before them;
+// every other vertex is reported as common.Inf. Unreachable targets make the search run to
+// completion.
+func (a *DijkstraAlgorithm[W]) WithTargets(targets ...int) *DijkstraAlgorithm[W] {
+	a.targets = targets
+	return a
+}
 
-	for _, s := range a.sources {
-		if s >= 0 && s < a.graph.N {
-			dist[s] = 0
-			heap.Push(&pq, &common.DistEntry{Vertex: s, Dist: 0})
-		}
+// WithReverse turns the search into a single-destination search: it runs on the
+// transpose of the graph, so the configured sources act as destinations and the result
+// holds the distance from every vertex to the nearest of them. Result.Path then leads
+// from a vertex to its destination, see common.NewReverseResult.
+func (a *DijkstraAlgorithm[W]) WithReverse() *DijkstraAlgorithm[W] {
+	if !a.reverse && a.csr != nil {
+		a.csr = a.csr.Transpose()
 	}
+	a.reverse = true
+	return a
+}
 
-	for pq.Len() > 0 {
-		entry := heap.Pop(&pq).(*common.DistEntry)
-		u := entry.Vertex
-		d := entry.Dist
+// Solve executes Dijkstra's algorithm based on the configured sources and boundary.
+func (a *DijkstraAlgorithm[W]) Solve() (*common.Result[W], error) {
+	return a.SolveContext(context.Background())
+}
 
-		if d > dist[u] {
-			continue
+// SolveContext is like Solve but stops once ctx is done. It then returns ctx.Err()
+// together with the distances of the vertices settled so far; every other vertex is Inf.
+// Sources count as settled from the start, so they keep distance 0 even if ctx is
+// already done when SolveContext is called. BMSSP follows the same contract.
+func (a *DijkstraAlgorithm[W]) SolveContext(ctx context.Context) (*common.Result[W], error) {
+	if a.csr == nil {
+		csr, err := common.NewCSRGraph(a.graph)
+		if err != nil {
+			return nil, fmt.Errorf("dijkstra: %w", err)
 		}
-		if a.boundary != nil && dist[u] >= *a.boundary {
-			continue
+		if a.reverse {
+			csr = csr.Transpose()
 		}
+		a.graph, a.csr = nil, csr
+	}
+	if err := common.ValidateSources(a.csr, a.sources); err != nil {
+		return nil, fmt.Errorf("dijkstra: %w", err)
+	}
+	if err := common.ValidateTargets(a.csr, a.targets); err != nil {
+		return nil, fmt.Errorf("dijkstra: %w", err)
+	}
+	if a.boundary != nil && *a.boundary != *a.boundary {
+		return nil, fmt.Errorf("dijkstra: %w", &common.ParameterError{Name: "boundary", Value: *a.boundary, Reason: "must not be NaN"})
+	}
 
-		for _, edge := range a.graph.Adj[u] {
-			v := edge.V
-			newDist := dist[u] + edge.Weight
-
-			if a.boundary != nil && newDist >= *a.boundary {
-				continue
-			}
-			if newDist < dist[v] {
-				dist[v] = newDist
-				heap.Push(&pq, &common.DistEntry{Vertex: v, Dist: newDist})
-			}
-		}
+	bound := common.Inf[W]()
+	if a.boundary != nil {
+		bound = *a.boundary
 	}
+	w := &Workspace[W]{graph: a.csr, inf: common.Inf[W](), reverse: a.reverse}
+	err := w.query(ctx, a.sources, a.targets, bound)
+	return w.Result(), err
+}
 
-	return dist, nil
+// SolveLabeled runs an unbounded search on a labeled graph, taking sources and targets
+// by label. A non-empty target set stops the search early, see WithTargets.
+func SolveLabeled[K comparable, W common.Weight](g *common.LabeledGraph[K, W], sources []K, targets ...K) (*common.LabeledResult[K, W], error) {
+	src, err := g.Labels.IDs(sources)
+	if err != nil {
+		return nil, fmt.Errorf("dijkstra: %w", err)
+	}
+	dst, err := g.Labels.IDs(targets)
+	if err != nil {
+		return nil, fmt.Errorf("dijkstra: %w", err)
+	}
+	res, err := NewDijkstraAlgorithm(g.Graph, src, nil).WithTargets(dst...).Solve()
+	if err != nil {
+		return nil, err
+	}
+	return g.Result(res), nil
 }
@@ -1,8 +1,11 @@
 package dijkstra
 
 import (
+	"context"
+	"errors"
 	"math"
 	"playground/common"
+	"slices"
 	"testing"
 )
 
@@ -16,8 +19,8 @@ func TestDijkstra_SimpleGraph(t *testing.T) {
 
 	expected := map[int]float64{0: 0, 1: 1, 2: 2, 3: 3, 4: 4}
 	for v, exp := range expected {
-		if math.Abs(dist[v]-exp) > 1e-9 {
-			t.Errorf("Vertex %d: expected dist=%f, got %f", v, exp, dist[v])
+		if math.Abs(dist.Dist(v)-exp) > 1e-9 {
+			t.Errorf("Vertex %d: expected dist=%f, got %f", v, exp, dist.Dist(v))
 		}
 	}
 }
@@ -33,8 +36,8 @@ func TestDijkstra_MultiSource(t *testing.T) {
 
 	expected := map[int]float64{0: 0, 1: 1, 2: 2, 3: 1, 4: 0}
 	for v, exp := range expected {
-		if math.Abs(dist[v]-exp) > 1e-9 {
-			t.Errorf("Vertex %d: expected dist=%f, got %f", v, exp, dist[v])
+		if math.Abs(dist.Dist(v)-exp) > 1e-9 {
+			t.Errorf("Vertex %d: expected dist=%f, got %f", v, exp, dist.Dist(v))
 		}
 	}
 }
@@ -49,26 +52,26 @@ func TestDijkstra_Bounded(t *testing.T) {
 	}
 
 	for i := 0; i < 5; i++ {
-		if dist[i] != float64(i) {
-			t.Errorf("Vertex %d: expected dist=%f, got %f", i, float64(i), dist[i])
+		if dist.Dist(i) != float64(i) {
+			t.Errorf("Vertex %d: expected dist=%f, got %f", i, float64(i), dist.Dist(i))
 		}
 	}
 	for i := 5; i < 10; i++ {
-		if !math.IsInf(dist[i], 1) {
-			t.Errorf("Vertex %d beyond boundary should have infinite distance, got %f", i, dist[i])
+		if !math.IsInf(dist.Dist(i), 1) {
+			t.Errorf("Vertex %d beyond boundary should have infinite distance, got %f", i, dist.Dist(i))
 		}
 	}
 }
 
 func TestDijkstra_DisconnectedGraph(t *testing.T) {
-	g := &common.Graph{N: 6, Adj: make(map[int][]common.Edge)}
-	edges := []common.Edge{
+	g := &common.Graph[float64]{N: 6, Adj: make(map[int][]common.Edge[float64])}
+	edges := []common.Edge[float64]{
 		{U: 0, V: 1, Weight: 1.0}, {U: 1, V: 2, Weight: 1.0},
 		{U: 3, V: 4, Weight: 1.0}, {U: 4, V: 5, Weight: 1.0},
 	}
 	for _, e := range edges {
 		g.Adj[e.U] = append(g.Adj[e.U], e)
-		g.Adj[e.V] = append(g.Adj[e.V], common.Edge{U: e.V, V: e.U, Weight: e.Weight})
+		g.Adj[e.V] = append(g.Adj[e.V], common.Edge[float64]{U: e.V, V: e.U, Weight: e.Weight})
 	}
 
 	algo := NewDijkstraAlgorithm(g, []int{0}, nil)
@@ -77,10 +80,10 @@ func TestDijkstra_DisconnectedGraph(t *testing.T) {
 		t.Fatalf("Solve() returned an error: %v", err)
 	}
 
-	if math.IsInf(dist[1], 1) || math.IsInf(dist[2], 1) {
+	if math.IsInf(dist.Dist(1), 1) || math.IsInf(dist.Dist(2), 1) {
 		t.Error("Connected vertices have infinite distance")
 	}
-	if !math.IsInf(dist[3], 1) || !math.IsInf(dist[4], 1) || !math.IsInf(dist[5], 1) {
+	if !math.IsInf(dist.Dist(3), 1) || !math.IsInf(dist.Dist(4), 1) || !math.IsInf(dist.Dist(5), 1) {
 		t.Error("Disconnected vertices have finite distance")
 	}
 }
@@ -95,8 +98,8 @@ func TestDijkstra_CycleGraph(t *testing.T) {
 
 	expected := map[int]float64{0: 0, 1: 1, 2: 3, 3: 4}
 	for v, exp := range expected {
-		if math.Abs(dist[v]-exp) > 1e-9 {
-			t.Errorf("Vertex %d: expected dist=%f, got %f", v, exp, dist[v])
+		if math.Abs(dist.Dist(v)-exp) > 1e-9 {
+			t.Errorf("Vertex %d: expected dist=%f, got %f", v, exp, dist.Dist(v))
 		}
 	}
 }
@@ -111,8 +114,426 @@ func TestDijkstra_WeightedCompleteGraph(t *testing.T) {
 
 	expected := map[int]float64{0: 0, 1: 1, 2: 3, 3: 4}
 	for v, exp := range expected {
-		if math.Abs(dist[v]-exp) > 1e-9 {
-			t.Errorf("Vertex %d: expected dist=%f, got %f", v, exp, dist[v])
+		if math.Abs(dist.Dist(v)-exp) > 1e-9 {
+			t.Errorf("Vertex %d: expected dist=%f, got %f", v, exp, dist.Dist(v))
+		}
+	}
+}
+
+func TestDijkstra_Validation(t *testing.T) {
+	g := createLinearGraph(5)
+	if _, err := NewDijkstraAlgorithm(g, nil, nil).Solve(); !errors.Is(err, common.ErrNoSources) {
+		t.Errorf("expected ErrNoSources, got %v", err)
+	}
+
+	var vertexErr *common.VertexError
+	_, err := NewDijkstraAlgorithm(g, []int{0, 5}, nil).Solve()
+	if !errors.Is(err, common.ErrSourceOutOfRange) || !errors.As(err, &vertexErr) || vertexErr.Vertex != 5 {
+		t.Errorf("expected ErrSourceOutOfRange for vertex 5, got %v", err)
+	}
+
+	boundary := math.NaN()
+	if _, err := NewDijkstraAlgorithm(g, []int{0}, &boundary).Solve(); !errors.Is(err, common.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+
+	g.Adj[3] = append(g.Adj[3], common.Edge[float64]{U: 3, V: 9, Weight: 1})
+	var edgeErr *common.EdgeError[float64]
+	_, err = NewDijkstraAlgorithm(g, []int{0}, nil).Solve()
+	if !errors.Is(err, common.ErrVertexOutOfRange) || !errors.As(err, &edgeErr) || edgeErr.Edge.V != 9 {
+		t.Errorf("expected ErrVertexOutOfRange for edge 3->9, got %v", err)
+	}
+
+	g = createLinearGraph(5)
+	g.Adj[1][0].Weight = -2
+	if _, err := NewDijkstraAlgorithm(g, []int{0}, nil).Solve(); !errors.Is(err, common.ErrNegativeWeight) {
+		t.Errorf("expected ErrNegativeWeight, got %v", err)
+	}
+}
+
+func TestDijkstra_RepeatedSolveReusesConversion(t *testing.T) {
+	g := createLinearGraph(20)
+	algo := NewDijkstraAlgorithm(g, []int{0}, nil)
+	first, err := algo.Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+
+	// The graph was converted by the first Solve, so later changes are not seen.
+	g.Adj[3][0].Weight = -1
+	second, err := algo.Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error on the second run: %v", err)
+	}
+	if !slices.Equal(first.Distances(), second.Distances()) {
+		t.Errorf("expected the same distances from both runs, got %v and %v", first.Distances(), second.Distances())
+	}
+}
+
+func TestDijkstra_SolveContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	g := createLinearGraph(20)
+	dist, err := NewDijkstraAlgorithm(g, []int{0}, nil).SolveContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if dist.Dist(0) != 0 {
+		t.Errorf("expected the source to stay settled, got %f", dist.Dist(0))
+	}
+	for v := 1; v < g.N; v++ {
+		if !math.IsInf(dist.Dist(v), 1) {
+			t.Errorf("vertex %d: expected +Inf after immediate cancellation, got %f", v, dist.Dist(v))
+		}
+	}
+}
+
+func TestDijkstra_SolveContext_PartialDistancesAreExact(t *testing.T) {
+	ctx := &countdownContext{Context: context.Background(), remaining: 4}
+	dist, err := NewDijkstraAlgorithm(createLinearGraph(10), []int{0}, nil).SolveContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	for v := 0; v < 10; v++ {
+		want := math.Inf(1)
+		if v < 4 {
+			want = float64(v)
+		}
+		if dist.Dist(v) != want {
+			t.Errorf("vertex %d: expected dist=%f, got %f", v, want, dist.Dist(v))
+		}
+	}
+}
+
+func TestDijkstra_Targets(t *testing.T) {
+	g := createLinearGraph(10)
+	dist, err := NewDijkstraAlgorithm(g, []int{0}, nil).WithTargets(5, 2, 5).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	for v := 0; v < 10; v++ {
+		want := math.Inf(1)
+		if v <= 5 {
+			want = float64(v)
+		}
+		if dist.Dist(v) != want {
+			t.Errorf("vertex %d: expected dist=%f, got %f", v, want, dist.Dist(v))
+		}
+	}
+
+	// An unreachable target lets the search run to completion.
+	isolated := createLinearGraph(4)
+	isolated.N = 6
+	dist, err = NewDijkstraAlgorithm(isolated, []int{0}, nil).WithTargets(1, 5).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	if dist.Dist(3) != 3 || dist.Reachable(5) {
+		t.Errorf("expected a complete search from vertex 0, got %v", dist.Map())
+	}
+
+	_, err = NewDijkstraAlgorithm(g, []int{0}, nil).WithTargets(10).Solve()
+	if !errors.Is(err, common.ErrTargetOutOfRange) {
+		t.Errorf("expected ErrTargetOutOfRange, got %v", err)
+	}
+}
+
+func TestDijkstra_CSR(t *testing.T) {
+	g := createWeightedCompleteGraph()
+	csr, err := common.NewCSRGraph(g)
+	if err != nil {
+		t.Fatalf("NewCSRGraph() returned an error: %v", err)
+	}
+	want, err := NewDijkstraAlgorithm(g, []int{0}, nil).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	got, err := NewDijkstraAlgorithmCSR(csr, []int{0}, nil).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	for v := 0; v < g.N; v++ {
+		if got.Dist(v) != want.Dist(v) {
+			t.Errorf("vertex %d: expected dist=%f, got %f", v, want.Dist(v), got.Dist(v))
+		}
+	}
+
+	// Converting back yields a graph with the same adjacency.
+	back := csr.Graph()
+	if err := common.ValidateGraph(back); err != nil {
+		t.Fatalf("ValidateGraph() returned an error: %v", err)
+	}
+	for u := 0; u < g.N; u++ {
+		if !slices.Equal(back.Adj[u], g.Adj[u]) {
+			t.Errorf("vertex %d: expected adjacency %v, got %v", u, g.Adj[u], back.Adj[u])
+		}
+	}
+}
+
+func TestDijkstra_GraphBuilder(t *testing.T) {
+	b := common.NewGraphBuilder[float64](true).
+		AddEdge(0, 1, 4).
+		AddEdge(0, 1, 2).
+		AddUndirectedEdge(1, 2, 1).
+		AddEdge(2, 2, 0)
+	g, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() returned an error: %v", err)
+	}
+	if g.N != 3 || !g.Directed || len(g.Edges) != 5 {
+		t.Errorf("expected N=3, a directed graph and 5 edges, got N=%d, Directed=%v and %v", g.N, g.Directed, g.Edges)
+	}
+	adjEdges := 0
+	for _, adj := range g.Adj {
+		adjEdges += len(adj)
+	}
+	if adjEdges != len(g.Edges) {
+		t.Errorf("expected Adj to hold the %d edges of Edges, got %d", len(g.Edges), adjEdges)
+	}
+	dist, err := NewDijkstraAlgorithm(g, []int{2}, nil).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	if dist.Dist(1) != 1 || dist.Reachable(0) {
+		t.Errorf("expected vertex 1 at dist=1 and vertex 0 unreachable, got %v", dist.Map())
+	}
+
+	lightest, err := b.WithDuplicates(common.KeepLightest).Build()
+	if err != nil {
+		t.Fatalf("Build() returned an error: %v", err)
+	}
+	if !slices.Equal(lightest.Adj[0], []common.Edge[float64]{{U: 0, V: 1, Weight: 2}}) {
+		t.Errorf("expected only the lightest 0->1 edge, got %v", lightest.Adj[0])
+	}
+	if _, err := b.WithDuplicates(common.RejectDuplicates).Build(); !errors.Is(err, common.ErrDuplicateEdge) {
+		t.Errorf("expected ErrDuplicateEdge, got %v", err)
+	}
+	if _, err := b.WithDuplicates(common.KeepDuplicates).WithVertices(2).Build(); !errors.Is(err, common.ErrVertexOutOfRange) {
+		t.Errorf("expected ErrVertexOutOfRange, got %v", err)
+	}
+
+	undirected, err := common.NewGraphBuilder[float64](false).WithVertices(4).AddEdge(0, 1, 1).Build()
+	if err != nil {
+		t.Fatalf("Build() returned an error: %v", err)
+	}
+	if undirected.N != 4 || undirected.Directed || len(undirected.Adj[1]) != 1 {
+		t.Errorf("expected 4 vertices and a reverse edge 1->0, got N=%d and %v", undirected.N, undirected.Adj)
+	}
+}
+
+func TestDijkstra_SolveLabeled(t *testing.T) {
+	type city struct{ name string }
+	g, err := common.NewLabeledGraphBuilder[city, float64](true).
+		AddEdge(city{"A"}, city{"B"}, 1).
+		AddEdge(city{"B"}, city{"C"}, 2).
+		AddEdge(city{"C"}, city{"D"}, 1).
+		AddEdge(city{"A"}, city{"D"}, 4).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() returned an error: %v", err)
+	}
+
+	res, err := SolveLabeled(g, []city{{"A"}}, city{"B"})
+	if err != nil {
+		t.Fatalf("SolveLabeled() returned an error: %v", err)
+	}
+	if res.DistOf(city{"B"}) != 1 || res.DistOf(city{"Z"}) != math.Inf(1) {
+		t.Errorf("expected B at 1 and the unknown Z at +Inf, got %v", res.LabelMap())
+	}
+	for c := range res.ReachedLabels() {
+		if c != (city{"A"}) && c != (city{"B"}) {
+			t.Errorf("expected the search to stop after B, but %v was reached", c)
+		}
+	}
+
+	if _, err := SolveLabeled(g, []city{{"Z"}}); !errors.Is(err, common.ErrUnknownLabel) {
+		t.Errorf("expected ErrUnknownLabel, got %v", err)
+	}
+}
+
+func TestWorkspace_RepeatedQueries(t *testing.T) {
+	g := createLinearGraph(10)
+	w, err := NewWorkspace(g)
+	if err != nil {
+		t.Fatalf("NewWorkspace() returned an error: %v", err)
+	}
+
+	if err := w.Query([]int{0}, math.Inf(1)); err != nil {
+		t.Fatalf("Query() returned an error: %v", err)
+	}
+	if w.Dist(9) != 9 {
+		t.Errorf("vertex 9: expected dist=9, got %f", w.Dist(9))
+	}
+
+	if err := w.Query([]int{9}, 3); err != nil {
+		t.Fatalf("Query() returned an error: %v", err)
+	}
+	for v := 0; v < 10; v++ {
+		want := math.Inf(1)
+		if 9-v < 3 {
+			want = float64(9 - v)
+		}
+		if w.Dist(v) != want {
+			t.Errorf("vertex %d: expected dist=%f, got %f", v, want, w.Dist(v))
+		}
+	}
+}
+
+func TestWorkspace_Targets(t *testing.T) {
+	g := createLinearGraph(50)
+	w, err := NewWorkspace(g)
+	if err != nil {
+		t.Fatalf("NewWorkspace() returned an error: %v", err)
+	}
+
+	if err := w.Query([]int{0}, math.Inf(1), 3); err != nil {
+		t.Fatalf("Query() returned an error: %v", err)
+	}
+	if w.Dist(3) != 3 {
+		t.Errorf("vertex 3: expected dist=3, got %f", w.Dist(3))
+	}
+	if !math.IsInf(w.Dist(4), 1) || !math.IsInf(w.Dist(49), 1) {
+		t.Errorf("expected the query to stop at vertex 3, got %f and %f", w.Dist(4), w.Dist(49))
+	}
+
+	// A query without targets must not inherit the previous ones.
+	if err := w.Query([]int{0}, math.Inf(1)); err != nil {
+		t.Fatalf("Query() returned an error: %v", err)
+	}
+	if w.Dist(49) != 49 {
+		t.Errorf("vertex 49: expected dist=49, got %f", w.Dist(49))
+	}
+
+	if err := w.Query([]int{0}, math.Inf(1), 50); !errors.Is(err, common.ErrTargetOutOfRange) {
+		t.Errorf("expected ErrTargetOutOfRange, got %v", err)
+	}
+}
+
+func TestWorkspace_QueryDoesNotAllocate(t *testing.T) {
+	w, err := NewWorkspace(createLinearGraph(1000))
+	if err != nil {
+		t.Fatalf("NewWorkspace() returned an error: %v", err)
+	}
+	_ = w.Query([]int{0}, math.Inf(1))
+
+	allocs := testing.AllocsPerRun(50, func() {
+		if err := w.Query([]int{500}, math.Inf(1)); err != nil {
+			t.Fatalf("Query() returned an error: %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expected Query to be allocation-free, got %v allocs per run", allocs)
+	}
+}
+
+func TestDijkstra_Result(t *testing.T) {
+	g := createLinearGraph(6)
+	boundary := 4.0
+	res, err := NewDijkstraAlgorithm(g, []int{1}, &boundary).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+
+	if res.Len() != 6 || !res.Reachable(4) || res.Reachable(5) || res.Reachable(-1) {
+		t.Fatalf("unexpected reachability: %v", res.Distances())
+	}
+	reached := 0
+	for v, d := range res.Reached() {
+		if d != math.Abs(float64(v-1)) {
+			t.Errorf("vertex %d: expected dist=%f, got %f", v, math.Abs(float64(v-1)), d)
+		}
+		reached++
+	}
+	if reached != 5 {
+		t.Errorf("expected 5 reached vertices, got %d", reached)
+	}
+
+	vertices, edges, ok := res.Path(4)
+	if !ok || len(vertices) != 4 || vertices[0] != 1 || vertices[3] != 4 || len(edges) != 3 {
+		t.Errorf("expected path 1->4, got %v %v (ok=%v)", vertices, edges, ok)
+	}
+	if _, ok := res.Pred(1); ok {
+		t.Error("expected no predecessor for the source")
+	}
+
+	m := res.Map()
+	if len(m) != 6 || m[0] != 1 || !math.IsInf(m[5], 1) {
+		t.Errorf("unexpected map adapter output: %v", m)
+	}
+}
+
+func TestDijkstra_IntegerWeightsSaturate(t *testing.T) {
+	maxW := common.Inf[uint32]() - 1
+	g := mustBuild(common.NewGraphBuilder[uint32](true).
+		AddEdge(0, 1, maxW).
+		AddEdge(1, 2, maxW).
+		AddEdge(0, 3, 7).
+		AddEdge(3, 4, 9))
+
+	res, err := NewDijkstraAlgorithm(g, []int{0}, nil).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	want := []uint32{0, maxW, common.Inf[uint32](), 7, 16}
+	if got := res.Distances(); !slices.Equal(got, want) {
+		t.Errorf("expected distances %v, got %v", want, got)
+	}
+	if res.Reachable(2) {
+		t.Error("expected vertex 2 to be unreachable once its distance overflows")
+	}
+
+	if _, err := common.NewGraphBuilder[int32](true).AddEdge(0, 1, common.Inf[int32]()).Build(); !errors.Is(err, common.ErrInvalidWeight) {
+		t.Errorf("expected ErrInvalidWeight for an infinite int32 weight, got %v", err)
+	}
+}
+
+func TestDijkstra_Reverse(t *testing.T) {
+	// 1 -> 0 <- 2, 3 -> 1, 0 -> 3: only edges into 0 count toward it.
+	g := mustBuild(common.NewGraphBuilder[float64](true).
+		AddEdge(1, 0, 2).
+		AddEdge(2, 0, 7).
+		AddEdge(3, 1, 1).
+		AddEdge(0, 3, 4).
+		WithVertices(5))
+
+	csr, err := common.NewCSRGraph(g)
+	if err != nil {
+		t.Fatalf("NewCSRGraph() returned an error: %v", err)
+	}
+	tr := csr.Transpose()
+	if tr.NumEdges() != csr.NumEdges() || tr.Degree(0) != 2 || tr.Degree(3) != 1 {
+		t.Fatalf("expected in-degrees 2 and 1 for vertices 0 and 3, got %d and %d", tr.Degree(0), tr.Degree(3))
+	}
+
+	want := []float64{0, 2, 7, 3, math.Inf(1)}
+	results := map[string]*common.Result[float64]{}
+	results["Graph"], err = NewDijkstraAlgorithm(g, []int{0}, nil).WithReverse().Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	results["CSR"], err = NewDijkstraAlgorithmCSR(csr, []int{0}, nil).WithReverse().Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	ws, err := NewReverseWorkspace(g)
+	if err != nil {
+		t.Fatalf("NewReverseWorkspace() returned an error: %v", err)
+	}
+	if err := ws.Query([]int{0}, math.Inf(1)); err != nil {
+		t.Fatalf("Query() returned an error: %v", err)
+	}
+	results["Workspace"] = ws.Result()
+
+	for name, res := range results {
+		if got := res.Distances(); !slices.Equal(got, want) {
+			t.Errorf("%s: expected distances %v, got %v", name, want, got)
+		}
+		vertices, edges, ok := res.Path(3)
+		if !ok || !slices.Equal(vertices, []int{3, 1, 0}) {
+			t.Errorf("%s: expected path 3-1-0, got %v (ok=%v)", name, vertices, ok)
+		} else if edges[0] != (common.Edge[float64]{U: 3, V: 1, Weight: 1}) {
+			t.Errorf("%s: expected first edge 3->1, got %v", name, edges[0])
 		}
 	}
 }
@@ -172,49 +593,55 @@ func BenchmarkDijkstra_Bounded(b *testing.B) {
 
 // --- Helper Functions ---
 
-func createLinearGraph(n int) *common.Graph {
-	g := &common.Graph{
-		N:   n,
-		Adj: make(map[int][]common.Edge),
+// countdownContext reports cancellation once Err has been called remaining times.
+type countdownContext struct {
+	context.Context
+	remaining int
+}
+
+func (c *countdownContext) Err() error {
+	if c.remaining <= 0 {
+		return context.Canceled
 	}
-	edges := make([]common.Edge, 0, n-1)
+	c.remaining--
+	return nil
+}
+
+func createLinearGraph(n int) *common.Graph[float64] {
+	b := common.NewGraphBuilder[float64](false).WithVertices(n)
 	for i := 0; i < n-1; i++ {
-		edges = append(edges, common.Edge{U: i, V: i + 1, Weight: 1.0})
-	}
-	for _, e := range edges {
-		g.Adj[e.U] = append(g.Adj[e.U], e)
-		g.Adj[e.V] = append(g.Adj[e.V], common.Edge{U: e.V, V: e.U, Weight: e.Weight})
+		b.AddEdge(i, i+1, 1.0)
 	}
-	g.Edges = edges
-	return g
+	return mustBuild(b)
 }
 
-func createCycleGraph() *common.Graph {
-	g := &common.Graph{N: 4, Adj: make(map[int][]common.Edge)}
-	edges := []common.Edge{
-		{U: 0, V: 1, Weight: 1.0}, {U: 1, V: 2, Weight: 2.0},
-		{U: 2, V: 3, Weight: 1.0}, {U: 3, V: 0, Weight: 5.0},
-	}
-	for _, e := range edges {
-		g.Adj[e.U] = append(g.Adj[e.U], e)
-		g.Adj[e.V] = append(g.Adj[e.V], common.Edge{U: e.V, V: e.U, Weight: e.Weight})
-	}
-	g.Edges = edges
-	return g
+func createCycleGraph() *common.Graph[float64] {
+	return mustBuild(common.NewGraphBuilder[float64](false).
+		AddEdge(0, 1, 1.0).
+		AddEdge(1, 2, 2.0).
+		AddEdge(2, 3, 1.0).
+		AddEdge(3, 0, 5.0))
 }
 
-func createWeightedCompleteGraph() *common.Graph {
-	g := &common.Graph{N: 4, Adj: make(map[int][]common.Edge)}
+func createWeightedCompleteGraph() *common.Graph[float64] {
 	weights := [][]float64{
 		{0, 1, 3, 7}, {1, 0, 2, 5},
 		{3, 2, 0, 1}, {7, 5, 1, 0},
 	}
+	b := common.NewGraphBuilder[float64](false)
 	for i := 0; i < 4; i++ {
 		for j := i + 1; j < 4; j++ {
-			w := weights[i][j]
-			g.Adj[i] = append(g.Adj[i], common.Edge{U: i, V: j, Weight: w})
-			g.Adj[j] = append(g.Adj[j], common.Edge{U: j, V: i, Weight: w})
+			b.AddEdge(i, j, weights[i][j])
 		}
 	}
+	return mustBuild(b)
+}
+
+// mustBuild builds a graph from a helper's fixed, valid edge list.
+func mustBuild[W common.Weight](b *common.GraphBuilder[W]) *common.Graph[W] {
+	g, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
 	return g
 }
@@ -0,0 +1,202 @@
+package dijkstra
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"playground/common"
+)
+
+// Workspace answers repeated Dijkstra queries against one graph. Its distance array
+// and priority queue are kept between queries and invalidated through epoch stamps,
+// so starting a query costs nothing and, once the queue has grown to its working
+// size, Query does not allocate.
+type Workspace[W common.Weight] struct {
+	graph   *common.CSRGraph[W] // The transpose of the bound graph if reverse is set.
+	reverse bool
+	inf     W
+	dist    []W
+	pred    []common.Edge[W] // Valid for reached vertices; U < 0 for sources.
+	reached common.StampSet  // Vertices whose dist and pred entries belong to the current query.
+	touched []int            // Every vertex added to reached by the current query.
+	settled common.StampSet
+	targets common.StampSet // Targets of the current query that are not yet settled.
+	pq      common.PriorityQueue[W]
+	entries common.EntryPool[W]
+}
+
+// NewWorkspace validates g once, converts it into a CSRGraph and binds a Workspace to
+// it. Later changes to g are not seen by the Workspace.
+func NewWorkspace[W common.Weight](g *common.Graph[W]) (*Workspace[W], error) {
+	csr, err := common.NewCSRGraph(g)
+	if err != nil {
+		return nil, fmt.Errorf("dijkstra: %w", err)
+	}
+	return NewWorkspaceCSR(csr), nil
+}
+
+// NewWorkspaceCSR binds a Workspace to g, which needs no validation or conversion.
+func NewWorkspaceCSR[W common.Weight](g *common.CSRGraph[W]) *Workspace[W] {
+	return &Workspace[W]{graph: g, inf: common.Inf[W]()}
+}
+
+// NewReverseWorkspace is like NewWorkspace but answers single-destination queries:
+// Query then computes the distance from every vertex to the nearest of the given
+// destinations, see DijkstraAlgorithm.WithReverse.
+func NewReverseWorkspace[W common.Weight](g *common.Graph[W]) (*Workspace[W], error) {
+	csr, err := common.NewCSRGraph(g)
+	if err != nil {
+		return nil, fmt.Errorf("dijkstra: %w", err)
+	}
+	return NewReverseWorkspaceCSR(csr), nil
+}
+
+// NewReverseWorkspaceCSR is like NewWorkspaceCSR but answers single-destination queries.
+func NewReverseWorkspaceCSR[W common.Weight](g *common.CSRGraph[W]) *Workspace[W] {
+	return &Workspace[W]{graph: g.Transpose(), reverse: true, inf: common.Inf[W]()}
+}
+
+// Query computes the distances from sources of every vertex closer than bound.
+// Pass common.Inf[W]() as bound for an unbounded search. Targets, if given, stop the
+// query as soon as all of them are settled, see DijkstraAlgorithm.WithTargets.
+func (w *Workspace[W]) Query(sources []int, bound W, targets ...int) error {
+	return w.QueryContext(context.Background(), sources, bound, targets...)
+}
+
+// QueryContext is like Query but stops once ctx is done, see DijkstraAlgorithm.SolveContext.
+func (w *Workspace[W]) QueryContext(ctx context.Context, sources []int, bound W, targets ...int) error {
+	if err := common.ValidateSources(w.graph, sources); err != nil {
+		return fmt.Errorf("dijkstra: %w", err)
+	}
+	if err := common.ValidateTargets(w.graph, targets); err != nil {
+		return fmt.Errorf("dijkstra: %w", err)
+	}
+	if bound != bound {
+		return fmt.Errorf("dijkstra: %w", &common.ParameterError{Name: "boundary", Value: bound, Reason: "must not be NaN"})
+	}
+	return w.query(ctx, sources, targets, bound)
+}
+
+// Dist returns the distance of v found by the last query, Inf if v was not reached.
+func (w *Workspace[W]) Dist(v int) W {
+	if v < 0 || v >= w.graph.NumVertices() || !w.reached.Has(v) {
+		return w.inf
+	}
+	return w.dist[v]
+}
+
+// Result copies the distances and predecessor edges of the last query into a Result.
+func (w *Workspace[W]) Result() *common.Result[W] {
+	n := w.graph.NumVertices()
+	dist := make([]W, n)
+	pred := make([]common.Edge[W], n)
+	for v := range dist {
+		dist[v] = w.distance(v)
+		pred[v] = common.Edge[W]{U: -1, V: v}
+		if w.reached.Has(v) {
+			pred[v] = w.pred[v]
+		}
+	}
+	if w.reverse {
+		return common.NewReverseResult(dist, pred)
+	}
+	return common.NewResult(dist, pred)
+}
+
+func (w *Workspace[W]) distance(v int) W {
+	if w.reached.Has(v) {
+		return w.dist[v]
+	}
+	return w.inf
+}
+
+// query runs Dijkstra on validated input. If targets is non-empty the search stops as
+// soon as all of them are settled. On cancellation or early termination only settled
+// vertices keep their distance.
+func (w *Workspace[W]) query(ctx context.Context, sources, targets []int, bound W) error {
+	n := w.graph.NumVertices()
+	if len(w.dist) < n {
+		w.dist = make([]W, n)
+		w.pred = make([]common.Edge[W], n)
+	}
+	w.reached.Reset(n)
+	w.touched = w.touched[:0]
+	w.settled.Reset(n)
+	w.pq = w.pq[:0]
+	w.entries.Reset()
+
+	w.targets.Reset(n)
+	remaining := 0
+	for _, t := range targets {
+		if w.targets.Add(t) {
+			remaining++
+		}
+	}
+
+	// Sources are settled before the first pop, so that a search cancelled right away
+	// still reports them, as BMSSP does.
+	for _, s := range sources {
+		w.dist[s] = 0
+		w.pred[s] = common.Edge[W]{U: -1, V: s}
+		if w.reached.Add(s) {
+			w.touched = append(w.touched, s)
+		}
+		w.settled.Add(s)
+		heap.Push(&w.pq, w.entries.Get(s, 0))
+	}
+
+	for w.pq.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			w.dropUnsettled()
+			return err
+		}
+
+		entry := heap.Pop(&w.pq).(*common.DistEntry[W])
+		u := entry.Vertex
+		d := entry.Dist
+
+		if d > w.dist[u] {
+			continue
+		}
+		if d >= bound {
+			continue
+		}
+		w.settled.Add(u)
+		if remaining > 0 && w.targets.Has(u) {
+			w.targets.Remove(u)
+			if remaining--; remaining == 0 {
+				w.dropUnsettled()
+				return nil
+			}
+		}
+
+		targets, weights := w.graph.Neighbors(u)
+		for i, t := range targets {
+			v := int(t)
+			newDist := common.AddWeights(d, weights[i])
+
+			if newDist >= bound {
+				continue
+			}
+			if newDist < w.distance(v) {
+				w.dist[v] = newDist
+				w.pred[v] = common.Edge[W]{U: u, V: v, Weight: weights[i]}
+				if w.reached.Add(v) {
+					w.touched = append(w.touched, v)
+				}
+				heap.Push(&w.pq, w.entries.Get(v, newDist))
+			}
+		}
+	}
+	return nil
+}
+
+// dropUnsettled forgets every tentative distance once the search stops early. It only
+// visits the vertices the query reached, so an early stop costs no O(N) scan.
+func (w *Workspace[W]) dropUnsettled() {
+	for _, v := range w.touched {
+		if !w.settled.Has(v) {
+			w.reached.Remove(v)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Package dimacs reads and writes the file formats of the 9th DIMACS Implementation
+// Challenge on shortest paths: .gr graphs, .co coordinates and .ss/.p2p query files,
+// and runs query files against the solvers. Vertex ids are 1-based in the files and
+// 0-based in memory. Readers stream their input line by line and report problems as
+// a ParseError carrying the line number.
+package dimacs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"playground/common"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrSyntax is reported for lines that do not follow the format.
+	ErrSyntax = errors.New("syntax error")
+	// ErrNoProblemLine is reported for files without a "p" line before the data.
+	ErrNoProblemLine = errors.New("missing problem line")
+	// ErrCountMismatch is reported when the number of data lines differs from the
+	// count announced by the problem line.
+	ErrCountMismatch = errors.New("line count does not match problem line")
+)
+
+// maxPrealloc caps the capacity reserved for the count announced by a problem line,
+// so that a forged count cannot force a large allocation before any data is read.
+const maxPrealloc = 1 << 16
+
+// ParseError reports a problem on a line of an input file.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// lineReader yields the fields of every line that is neither blank nor a comment.
+type lineReader struct {
+	s      *bufio.Scanner
+	line   int
+	fields []string
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &lineReader{s: s}
+}
+
+func (lr *lineReader) scan() bool {
+	for lr.s.Scan() {
+		lr.line++
+		lr.fields = strings.Fields(lr.s.Text())
+		if len(lr.fields) > 0 && lr.fields[0] != "c" {
+			return true
+		}
+	}
+	return false
+}
+
+// errorf returns a ParseError for the current line.
+func (lr *lineReader) errorf(err error, format string, args ...any) error {
+	return fmt.Errorf("dimacs: %w", &ParseError{Line: lr.line, Err: fmt.Errorf("%w: "+format, append([]any{err}, args...)...)})
+}
+
+// err returns the error of the underlying reader, if any.
+func (lr *lineReader) err() error {
+	if err := lr.s.Err(); err != nil {
+		return fmt.Errorf("dimacs: %w", err)
+	}
+	return nil
+}
+
+// problem parses a problem line "p <words...> <count...>" and returns the counts.
+func (lr *lineReader) problem(words []string, counts int) ([]int, error) {
+	f := lr.fields
+	if len(f) != 1+len(words)+counts {
+		return nil, lr.errorf(ErrSyntax, "expected \"p %s\" followed by %d counts", strings.Join(words, " "), counts)
+	}
+	for i, w := range words {
+		if f[1+i] != w {
+			return nil, lr.errorf(ErrSyntax, "expected \"p %s\", got %q", strings.Join(words, " "), strings.Join(f, " "))
+		}
+	}
+	out := make([]int, counts)
+	for i := range out {
+		n, err := strconv.Atoi(f[1+len(words)+i])
+		if err != nil || n < 0 {
+			return nil, lr.errorf(ErrSyntax, "invalid count %q", f[1+len(words)+i])
+		}
+		out[i] = n
+	}
+	return out, nil
+}
+
+// vertices checks a vertex count from a problem line against the int32 ids of a
+// CSRGraph.
+func (lr *lineReader) vertices(n int) error {
+	if n > math.MaxInt32 {
+		return fmt.Errorf("dimacs: %w", &ParseError{Line: lr.line, Err: &common.ParameterError{Name: "N", Value: n, Reason: fmt.Sprintf("at most %d vertices are supported", math.MaxInt32)}})
+	}
+	return nil
+}
+
+// vertex parses a 1-based vertex id, checking it against n if n is non-negative,
+// and returns it 0-based.
+func (lr *lineReader) vertex(s string, n int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, lr.errorf(ErrSyntax, "invalid vertex %q", s)
+	}
+	if v < 1 || (n >= 0 && v > n) {
+		return 0, fmt.Errorf("dimacs: %w", &ParseError{Line: lr.line, Err: &common.VertexError{Vertex: v, Err: common.ErrVertexOutOfRange}})
+	}
+	return v - 1, nil
+}
+
+// ReadGraph reads a .gr file: a problem line "p sp N M" followed by M arc lines
+// "a U V W". Arcs are directed; the graph keeps them in file order.
+func ReadGraph[W common.Weight](r io.Reader) (*common.Graph[W], error) {
+	lr := newLineReader(r)
+	var b *common.GraphBuilder[W]
+	n, m, arcs := 0, 0, 0
+	for lr.scan() {
+		switch f := lr.fields; f[0] {
+		case "p":
+			if b != nil {
+				return nil, lr.errorf(ErrSyntax, "second problem line")
+			}
+			counts, err := lr.problem([]string{"sp"}, 2)
+			if err != nil {
+				return nil, err
+			}
+			n, m = counts[0], counts[1]
+			if err := lr.vertices(n); err != nil {
+				return nil, err
+			}
+			b = common.NewGraphBuilder[W](true).WithVertices(n)
+		case "a":
+			if b == nil {
+				return nil, lr.errorf(ErrNoProblemLine, "arc before \"p sp\"")
+			}
+			if len(f) != 4 {
+				return nil, lr.errorf(ErrSyntax, "expected \"a U V W\"")
+			}
+			u, err := lr.vertex(f[1], n)
+			if err != nil {
+				return nil, err
+			}
+			v, err := lr.vertex(f[2], n)
+			if err != nil {
+				return nil, err
+			}
+			w, err := common.ParseWeight[W](f[3])
+			if err != nil {
+				return nil, lr.errorf(ErrSyntax, "invalid weight %q", f[3])
+			}
+			if err := common.CheckWeight(w); err != nil {
+				return nil, fmt.Errorf("dimacs: %w", &ParseError{Line: lr.line, Err: &common.EdgeError[W]{Edge: common.Edge[W]{U: u, V: v, Weight: w}, Err: err}})
+			}
+			b.AddEdge(u, v, w)
+			arcs++
+		default:
+			return nil, lr.errorf(ErrSyntax, "unknown line type %q", f[0])
+		}
+	}
+	if err := lr.err(); err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, lr.errorf(ErrNoProblemLine, "no \"p sp\" line")
+	}
+	if arcs != m {
+		return nil, lr.errorf(ErrCountMismatch, "read %d arcs, problem line announced %d", arcs, m)
+	}
+	g, err := b.Build()
+	if err != nil {
+		return nil, fmt.Errorf("dimacs: %w", err)
+	}
+	return g, nil
+}
+
+// WriteGraph writes g as a .gr file. Every entry of Adj becomes an arc, so an
+// undirected graph is written with an arc in each direction.
+func WriteGraph[W common.Weight](w io.Writer, g *common.Graph[W]) error {
+	m := 0
+	for u := 0; u < g.N; u++ {
+		m += len(g.Adj[u])
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p sp %d %d\n", g.N, m)
+	var buf []byte
+	for u := 0; u < g.N; u++ {
+		for _, e := range g.Adj[u] {
+			buf = append(buf[:0], "a "...)
+			buf = strconv.AppendInt(buf, int64(e.U+1), 10)
+			buf = append(buf, ' ')
+			buf = strconv.AppendInt(buf, int64(e.V+1), 10)
+			buf = append(buf, ' ')
+			buf = common.AppendWeight(buf, e.Weight)
+			buf = append(buf, '\n')
+			bw.Write(buf)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("dimacs: %w", err)
+	}
+	return nil
+}
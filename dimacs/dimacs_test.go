@@ -0,0 +1,256 @@
+package dimacs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"playground/common"
+	"playground/dijkstra"
+	"playground/internal/graphtest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const sampleGraph = `c 9th DIMACS Implementation Challenge: Shortest Paths
+c sample graph
+p sp 4 5
+c arcs
+a 1 2 7
+a 1 3 2
+a 3 2 3
+
+a 2 4 1
+a 4 1 10
+`
+
+func TestReadGraph(t *testing.T) {
+	g, err := ReadGraph[int64](strings.NewReader(sampleGraph))
+	if err != nil {
+		t.Fatalf("ReadGraph() returned an error: %v", err)
+	}
+	if g.N != 4 || !g.Directed || len(g.Edges) != 5 {
+		t.Fatalf("unexpected graph: N=%d, Directed=%v, %d edges", g.N, g.Directed, len(g.Edges))
+	}
+	if e := g.Adj[0][1]; e.U != 0 || e.V != 2 || e.Weight != 2 {
+		t.Errorf("expected the arc 1 -> 3 with weight 2 as vertex 0's second arc, got %+v", e)
+	}
+	res, err := dijkstra.NewDijkstraAlgorithm(g, []int{0}, nil).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	if want := []int64{0, 5, 2, 6}; !slices.Equal(res.Distances(), want) {
+		t.Errorf("expected distances %v, got %v", want, res.Distances())
+	}
+}
+
+func TestReadGraph_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  int
+		want  error
+	}{
+		{"no problem line", "c empty\n", 1, ErrNoProblemLine},
+		{"arc before problem line", "a 1 2 3\np sp 2 1\n", 1, ErrNoProblemLine},
+		{"wrong problem", "p max 2 1\n", 1, ErrSyntax},
+		{"unknown line", "p sp 2 1\nx 1 2\n", 2, ErrSyntax},
+		{"short arc", "p sp 2 1\na 1 2\n", 2, ErrSyntax},
+		{"vertex out of range", "p sp 2 1\nc\na 1 3 1\n", 3, common.ErrVertexOutOfRange},
+		{"zero vertex", "p sp 2 1\na 0 1 1\n", 2, common.ErrVertexOutOfRange},
+		{"bad weight", "p sp 2 1\na 1 2 x\n", 2, ErrSyntax},
+		{"negative weight", "p sp 2 1\na 1 2 -4\n", 2, common.ErrNegativeWeight},
+		{"infinite weight", "p sp 2 1\na 1 2 9223372036854775807\n", 2, common.ErrInvalidWeight},
+		{"too few arcs", "p sp 2 2\na 1 2 1\n", 2, ErrCountMismatch},
+		{"too many vertices", "c\np sp 9223372036854775807 0\n", 2, common.ErrInvalidParameter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadGraph[int64](strings.NewReader(tt.input))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			var pe *ParseError
+			if !errors.As(err, &pe) || pe.Line != tt.line {
+				t.Errorf("expected a ParseError on line %d, got %v", tt.line, err)
+			}
+		})
+	}
+
+	// A weight that does not fit the weight type is a syntax error, not a silent wrap.
+	if _, err := ReadGraph[int32](strings.NewReader("p sp 2 1\na 1 2 4294967296\n")); !errors.Is(err, ErrSyntax) {
+		t.Errorf("expected ErrSyntax for an int32 overflow, got %v", err)
+	}
+}
+
+func TestWriteGraph_RoundTrip(t *testing.T) {
+	testRoundTrip(t, graphtest.RandomFractional[int64](200, 800, true, 1))
+	testRoundTrip(t, graphtest.RandomFractional[uint32](50, 100, true, 2))
+	testRoundTrip(t, graphtest.RandomFractional[float64](50, 100, true, 3))
+	testRoundTrip(t, graphtest.RandomFractional[float32](50, 100, true, 4))
+}
+
+func TestAuxFiles_RoundTrip(t *testing.T) {
+	coords := []common.Coord{{X: -73530767, Y: 41085396}, {X: 0.5, Y: -2.25}, {}}
+	var buf bytes.Buffer
+	if err := WriteCoords(&buf, coords); err != nil {
+		t.Fatalf("WriteCoords() returned an error: %v", err)
+	}
+	gotCoords, err := ReadCoords(&buf)
+	if err != nil {
+		t.Fatalf("ReadCoords() returned an error: %v", err)
+	}
+	if !slices.Equal(gotCoords, coords) {
+		t.Errorf("expected coordinates %v, got %v", coords, gotCoords)
+	}
+
+	sources := []int{0, 41, 7}
+	buf.Reset()
+	if err := WriteSources(&buf, sources); err != nil {
+		t.Fatalf("WriteSources() returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "s 42\n") {
+		t.Errorf("expected 1-based ids in the .ss file, got %q", buf.String())
+	}
+	gotSources, err := ReadSources(&buf)
+	if err != nil {
+		t.Fatalf("ReadSources() returned an error: %v", err)
+	}
+	if !slices.Equal(gotSources, sources) {
+		t.Errorf("expected sources %v, got %v", sources, gotSources)
+	}
+
+	queries := []Query{{Source: 0, Target: 3}, {Source: 2, Target: 2}}
+	buf.Reset()
+	if err := WriteQueries(&buf, queries); err != nil {
+		t.Fatalf("WriteQueries() returned an error: %v", err)
+	}
+	gotQueries, err := ReadQueries(&buf)
+	if err != nil {
+		t.Fatalf("ReadQueries() returned an error: %v", err)
+	}
+	if !slices.Equal(gotQueries, queries) {
+		t.Errorf("expected queries %v, got %v", queries, gotQueries)
+	}
+
+	if _, err := ReadCoords(strings.NewReader("p aux sp co 2\nv 1 0 0\n")); !errors.Is(err, ErrCountMismatch) {
+		t.Errorf("expected ErrCountMismatch for a missing coordinate, got %v", err)
+	}
+	_, err = ReadCoords(strings.NewReader("p aux sp co 2\nv 1 0 0\nv 1 5 5\n"))
+	var pe *ParseError
+	if !errors.Is(err, ErrSyntax) || !errors.As(err, &pe) || pe.Line != 3 {
+		t.Errorf("expected ErrSyntax on line 3 for a vertex listed twice, got %v", err)
+	}
+	if _, err := ReadQueries(strings.NewReader("p aux sp ss 1\ns 1\n")); !errors.Is(err, ErrSyntax) {
+		t.Errorf("expected ErrSyntax for a .ss file read as .p2p, got %v", err)
+	}
+
+	// Counts from the problem line are not trusted for allocations.
+	const huge = "9223372036854775807"
+	if _, err := ReadCoords(strings.NewReader("p aux sp co " + huge + "\n")); !errors.Is(err, common.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for a vertex count beyond int32, got %v", err)
+	}
+	if _, err := ReadSources(strings.NewReader("p aux sp ss " + huge + "\ns 1\n")); !errors.Is(err, ErrCountMismatch) {
+		t.Errorf("expected ErrCountMismatch for a huge source count, got %v", err)
+	}
+	if _, err := ReadQueries(strings.NewReader("p aux sp p2p " + huge + "\nq 1 2\n")); !errors.Is(err, ErrCountMismatch) {
+		t.Errorf("expected ErrCountMismatch for a huge query count, got %v", err)
+	}
+	var many strings.Builder
+	const n = 3 * maxPrealloc
+	fmt.Fprintf(&many, "p aux sp co %d\n", n)
+	for v := n; v >= 1; v-- {
+		fmt.Fprintf(&many, "v %d %d 0\n", v, v)
+	}
+	gotCoords, err = ReadCoords(strings.NewReader(many.String()))
+	if err != nil {
+		t.Fatalf("ReadCoords() returned an error: %v", err)
+	}
+	if len(gotCoords) != n || gotCoords[0].X != 1 || gotCoords[n-1].X != n {
+		t.Errorf("expected %d coordinates in id order, got %d", n, len(gotCoords))
+	}
+}
+
+func TestRun_SolversAgree(t *testing.T) {
+	g := graphtest.RandomFractional[int64](300, 1200, true, 5)
+	csr, err := common.NewCSRGraph(g)
+	if err != nil {
+		t.Fatalf("NewCSRGraph() returned an error: %v", err)
+	}
+	sources := []int{0, 17, 299}
+	queries := []Query{{Source: 0, Target: 150}, {Source: 42, Target: 42}, {Source: 299, Target: 1}}
+
+	var outputs [2][2]string
+	for i, solver := range []Solver{Dijkstra, BMSSP} {
+		opts := RunOptions{Solver: solver, GraphFile: "random.gr", QueryFile: "random.ss"}
+		var buf bytes.Buffer
+		if err := RunSS(&buf, csr, sources, opts); err != nil {
+			t.Fatalf("%v: RunSS() returned an error: %v", solver, err)
+		}
+		outputs[i][0] = buf.String()
+		buf.Reset()
+		opts.QueryFile = "random.p2p"
+		if err := RunP2P(&buf, csr, queries, opts); err != nil {
+			t.Fatalf("%v: RunP2P() returned an error: %v", solver, err)
+		}
+		outputs[i][1] = buf.String()
+	}
+
+	ss := resultLines(outputs[0][0])
+	if ss[0] != "p res sp ss dijkstra" || ss[1] != "f random.gr random.ss" || !strings.HasPrefix(ss[2], "g 300 1200 ") {
+		t.Errorf("unexpected ss header %q", ss[:3])
+	}
+	if len(ss) != 4+len(sources) {
+		t.Fatalf("expected %d ss lines, got %d", 4+len(sources), len(ss))
+	}
+	ref := dijkstra.NewWorkspaceCSR(csr)
+	if err := ref.Query([]int{17}, common.Inf[int64]()); err != nil {
+		t.Fatalf("Query() returned an error: %v", err)
+	}
+	var sum int64
+	for v := 0; v < g.N; v++ {
+		if d := ref.Dist(v); !common.IsInf(d) {
+			sum += d
+		}
+	}
+	if want := "d " + strconv.FormatInt(sum, 10); ss[5] != want {
+		t.Errorf("expected checksum line %q, got %q", want, ss[5])
+	}
+
+	for kind := range 2 {
+		want, got := resultLines(outputs[0][kind]), resultLines(outputs[1][kind])
+		if !slices.Equal(want[4:], got[4:]) || want[2] != got[2] {
+			t.Errorf("bmssp and dijkstra results differ:\n%v\n%v", want, got)
+		}
+	}
+	if p2p := resultLines(outputs[1][1]); p2p[0] != "p res sp p2p bmssp" || p2p[5] != "d 43 43 0" {
+		t.Errorf("unexpected p2p output %q", p2p)
+	}
+
+	if err := RunSS(&bytes.Buffer{}, csr, []int{300}, RunOptions{}); !errors.Is(err, common.ErrSourceOutOfRange) {
+		t.Errorf("expected ErrSourceOutOfRange for a source beyond the graph, got %v", err)
+	}
+}
+
+// --- Helper Functions ---
+
+func testRoundTrip[W common.Weight](t *testing.T, g *common.Graph[W]) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteGraph(&buf, g); err != nil {
+		t.Fatalf("WriteGraph() returned an error: %v", err)
+	}
+	got, err := ReadGraph[W](&buf)
+	if err != nil {
+		t.Fatalf("ReadGraph() returned an error: %v", err)
+	}
+	if got.N != g.N || !slices.Equal(got.Edges, g.Edges) {
+		t.Fatalf("graph read back differs from the one written")
+	}
+}
+
+// resultLines splits a result file into lines, dropping the trailing newline.
+func resultLines(s string) []string {
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,217 @@
+package dimacs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"playground/common"
+	"strconv"
+)
+
+// Query is a point-to-point query of a .p2p file.
+type Query struct {
+	Source, Target int
+}
+
+// ReadCoords reads a .co file: a problem line "p aux sp co N" followed by lines
+// "v ID X Y" that list every vertex exactly once.
+func ReadCoords(r io.Reader) ([]common.Coord, error) {
+	lr := newLineReader(r)
+	var coords []common.Coord
+	var seen []uint64 // One bit per vertex, grown along with coords.
+	n, lines := -1, 0
+	for lr.scan() {
+		switch f := lr.fields; f[0] {
+		case "p":
+			if n >= 0 {
+				return nil, lr.errorf(ErrSyntax, "second problem line")
+			}
+			counts, err := lr.problem([]string{"aux", "sp", "co"}, 1)
+			if err != nil {
+				return nil, err
+			}
+			if err := lr.vertices(counts[0]); err != nil {
+				return nil, err
+			}
+			n = counts[0]
+			coords = make([]common.Coord, min(n, maxPrealloc))
+		case "v":
+			if n < 0 {
+				return nil, lr.errorf(ErrNoProblemLine, "coordinate before \"p aux sp co\"")
+			}
+			if len(f) != 4 {
+				return nil, lr.errorf(ErrSyntax, "expected \"v ID X Y\"")
+			}
+			v, err := lr.vertex(f[1], n)
+			if err != nil {
+				return nil, err
+			}
+			if v >= len(coords) {
+				coords = append(coords, make([]common.Coord, min(max(v+1, 2*len(coords)), n)-len(coords))...)
+			}
+			if words := (len(coords) + 63) / 64; words > len(seen) {
+				seen = append(seen, make([]uint64, words-len(seen))...)
+			}
+			if seen[v/64]&(1<<(v%64)) != 0 {
+				return nil, lr.errorf(ErrSyntax, "vertex %d listed twice", v+1)
+			}
+			seen[v/64] |= 1 << (v % 64)
+			x, errX := strconv.ParseFloat(f[2], 64)
+			y, errY := strconv.ParseFloat(f[3], 64)
+			if errX != nil || errY != nil || math.IsInf(x, 0) || math.IsInf(y, 0) || x != x || y != y {
+				return nil, lr.errorf(ErrSyntax, "invalid coordinates %q %q", f[2], f[3])
+			}
+			coords[v] = common.Coord{X: x, Y: y}
+			lines++
+		default:
+			return nil, lr.errorf(ErrSyntax, "unknown line type %q", f[0])
+		}
+	}
+	if err := lr.err(); err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, lr.errorf(ErrNoProblemLine, "no \"p aux sp co\" line")
+	}
+	if lines != n {
+		return nil, lr.errorf(ErrCountMismatch, "read %d coordinates, problem line announced %d", lines, n)
+	}
+	return append(coords, make([]common.Coord, n-len(coords))...), nil
+}
+
+// WriteCoords writes coords as a .co file.
+func WriteCoords(w io.Writer, coords []common.Coord) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p aux sp co %d\n", len(coords))
+	for v, c := range coords {
+		fmt.Fprintf(bw, "v %d %s %s\n", v+1,
+			strconv.FormatFloat(c.X, 'g', -1, 64), strconv.FormatFloat(c.Y, 'g', -1, 64))
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("dimacs: %w", err)
+	}
+	return nil
+}
+
+// ReadSources reads a .ss file: a problem line "p aux sp ss K" followed by K lines
+// "s ID". The ids are returned 0-based and checked against the graph by the solvers.
+func ReadSources(r io.Reader) ([]int, error) {
+	lr := newLineReader(r)
+	var sources []int
+	want := -1
+	for lr.scan() {
+		switch f := lr.fields; f[0] {
+		case "p":
+			if want >= 0 {
+				return nil, lr.errorf(ErrSyntax, "second problem line")
+			}
+			counts, err := lr.problem([]string{"aux", "sp", "ss"}, 1)
+			if err != nil {
+				return nil, err
+			}
+			want = counts[0]
+			sources = make([]int, 0, min(want, maxPrealloc))
+		case "s":
+			if want < 0 {
+				return nil, lr.errorf(ErrNoProblemLine, "source before \"p aux sp ss\"")
+			}
+			if len(f) != 2 {
+				return nil, lr.errorf(ErrSyntax, "expected \"s ID\"")
+			}
+			s, err := lr.vertex(f[1], -1)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, s)
+		default:
+			return nil, lr.errorf(ErrSyntax, "unknown line type %q", f[0])
+		}
+	}
+	if err := lr.err(); err != nil {
+		return nil, err
+	}
+	if want < 0 {
+		return nil, lr.errorf(ErrNoProblemLine, "no \"p aux sp ss\" line")
+	}
+	if len(sources) != want {
+		return nil, lr.errorf(ErrCountMismatch, "read %d sources, problem line announced %d", len(sources), want)
+	}
+	return sources, nil
+}
+
+// WriteSources writes 0-based sources as a .ss file.
+func WriteSources(w io.Writer, sources []int) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p aux sp ss %d\n", len(sources))
+	for _, s := range sources {
+		fmt.Fprintf(bw, "s %d\n", s+1)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("dimacs: %w", err)
+	}
+	return nil
+}
+
+// ReadQueries reads a .p2p file: a problem line "p aux sp p2p K" followed by K lines
+// "q S T". The ids are returned 0-based.
+func ReadQueries(r io.Reader) ([]Query, error) {
+	lr := newLineReader(r)
+	var queries []Query
+	want := -1
+	for lr.scan() {
+		switch f := lr.fields; f[0] {
+		case "p":
+			if want >= 0 {
+				return nil, lr.errorf(ErrSyntax, "second problem line")
+			}
+			counts, err := lr.problem([]string{"aux", "sp", "p2p"}, 1)
+			if err != nil {
+				return nil, err
+			}
+			want = counts[0]
+			queries = make([]Query, 0, min(want, maxPrealloc))
+		case "q":
+			if want < 0 {
+				return nil, lr.errorf(ErrNoProblemLine, "query before \"p aux sp p2p\"")
+			}
+			if len(f) != 3 {
+				return nil, lr.errorf(ErrSyntax, "expected \"q S T\"")
+			}
+			s, err := lr.vertex(f[1], -1)
+			if err != nil {
+				return nil, err
+			}
+			t, err := lr.vertex(f[2], -1)
+			if err != nil {
+				return nil, err
+			}
+			queries = append(queries, Query{Source: s, Target: t})
+		default:
+			return nil, lr.errorf(ErrSyntax, "unknown line type %q", f[0])
+		}
+	}
+	if err := lr.err(); err != nil {
+		return nil, err
+	}
+	if want < 0 {
+		return nil, lr.errorf(ErrNoProblemLine, "no \"p aux sp p2p\" line")
+	}
+	if len(queries) != want {
+		return nil, lr.errorf(ErrCountMismatch, "read %d queries, problem line announced %d", len(queries), want)
+	}
+	return queries, nil
+}
+
+// WriteQueries writes 0-based queries as a .p2p file.
+func WriteQueries(w io.Writer, queries []Query) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p aux sp p2p %d\n", len(queries))
+	for _, q := range queries {
+		fmt.Fprintf(bw, "q %d %d\n", q.Source+1, q.Target+1)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("dimacs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package dimacs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"playground/bmssp"
+	"playground/common"
+	"playground/dijkstra"
+	"strconv"
+	"time"
+)
+
+// Solver selects the algorithm a run uses.
+type Solver int
+
+const (
+	// Dijkstra runs the dijkstra package; point-to-point queries stop at their target.
+	Dijkstra Solver = iota
+	// BMSSP runs the bmssp package; point-to-point queries stop at their target.
+	BMSSP
+)
+
+func (s Solver) String() string {
+	switch s {
+	case Dijkstra:
+		return "dijkstra"
+	case BMSSP:
+		return "bmssp"
+	default:
+		return fmt.Sprintf("Solver(%d)", int(s))
+	}
+}
+
+// RunOptions configures RunSS and RunP2P.
+type RunOptions struct {
+	Solver Solver
+	// GraphFile and QueryFile are the names written to the "f" line of the result.
+	GraphFile, QueryFile string
+}
+
+// checksumModulus bounds the distance sums of single-source results, as the
+// challenge's reference checker does.
+const checksumModulus = 1 << 62
+
+// RunSS answers every source of a .ss file and writes the result in the challenge's
+// checksum format:
+//
+//	p res sp ss <solver>
+//	f <graph file> <query file>
+//	g <vertices> <arcs> <min weight> <max weight>
+//	t <mean milliseconds per source>
+//	d <sum of the distances of the reached vertices>   (one line per source)
+//
+// For integer weights the sums are taken modulo 2^62.
+func RunSS[W common.Weight](w io.Writer, g *common.CSRGraph[W], sources []int, opts RunOptions) error {
+	var (
+		query func(s int) error
+		dist  func(v int) W
+	)
+	switch opts.Solver {
+	case Dijkstra:
+		ws := dijkstra.NewWorkspaceCSR(g)
+		query = func(s int) error { return ws.Query([]int{s}, common.Inf[W]()) }
+		dist = ws.Dist
+	case BMSSP:
+		ws := bmssp.NewWorkspaceCSR(g)
+		query = func(s int) error { return ws.Query([]int{s}, common.Inf[W]()) }
+		dist = ws.Dist
+	default:
+		return fmt.Errorf("dimacs: %w", &common.ParameterError{Name: "solver", Value: opts.Solver, Reason: "unknown solver"})
+	}
+
+	sums := make([]string, len(sources))
+	var elapsed time.Duration
+	for i, s := range sources {
+		start := time.Now()
+		if err := query(s); err != nil {
+			return fmt.Errorf("dimacs: source %d: %w", s+1, err)
+		}
+		elapsed += time.Since(start)
+		sums[i] = checksum(g.NumVertices(), dist)
+	}
+
+	bw := bufio.NewWriter(w)
+	writeResultHeader(bw, "ss", g, opts, elapsed, len(sources))
+	for _, sum := range sums {
+		fmt.Fprintf(bw, "d %s\n", sum)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("dimacs: %w", err)
+	}
+	return nil
+}
+
+// RunP2P answers every query of a .p2p file and writes the result in the challenge's
+// format: the header lines of RunSS followed by one line "d <source> <target>
+// <distance>" per query, with 1-based ids. Unreachable targets are written with
+// distance -1.
+func RunP2P[W common.Weight](w io.Writer, g *common.CSRGraph[W], queries []Query, opts RunOptions) error {
+	// Both workspaces stop once the target is settled and answer Dist without copying
+	// the distances of every vertex.
+	var query func(q Query) (W, error)
+	switch opts.Solver {
+	case Dijkstra:
+		ws := dijkstra.NewWorkspaceCSR(g)
+		query = func(q Query) (W, error) {
+			if err := ws.Query([]int{q.Source}, common.Inf[W](), q.Target); err != nil {
+				return 0, err
+			}
+			return ws.Dist(q.Target), nil
+		}
+	case BMSSP:
+		ws := bmssp.NewWorkspaceCSR(g)
+		query = func(q Query) (W, error) {
+			if err := ws.Query([]int{q.Source}, common.Inf[W](), q.Target); err != nil {
+				return 0, err
+			}
+			return ws.Dist(q.Target), nil
+		}
+	default:
+		return fmt.Errorf("dimacs: %w", &common.ParameterError{Name: "solver", Value: opts.Solver, Reason: "unknown solver"})
+	}
+
+	dists := make([]W, len(queries))
+	var elapsed time.Duration
+	for i, q := range queries {
+		start := time.Now()
+		d, err := query(q)
+		if err != nil {
+			return fmt.Errorf("dimacs: query %d %d: %w", q.Source+1, q.Target+1, err)
+		}
+		elapsed += time.Since(start)
+		dists[i] = d
+	}
+
+	bw := bufio.NewWriter(w)
+	writeResultHeader(bw, "p2p", g, opts, elapsed, len(queries))
+	var buf []byte
+	for i, q := range queries {
+		buf = fmt.Appendf(buf[:0], "d %d %d ", q.Source+1, q.Target+1)
+		if common.IsInf(dists[i]) {
+			buf = append(buf, "-1"...)
+		} else {
+			buf = common.AppendWeight(buf, dists[i])
+		}
+		buf = append(buf, '\n')
+		bw.Write(buf)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("dimacs: %w", err)
+	}
+	return nil
+}
+
+// writeResultHeader writes the "p", "f", "g" and "t" lines shared by both result kinds.
+func writeResultHeader[W common.Weight](bw *bufio.Writer, kind string, g *common.CSRGraph[W], opts RunOptions, elapsed time.Duration, queries int) {
+	_, _, weights := g.Arrays()
+	var lo, hi W
+	for i, w := range weights {
+		if i == 0 || w < lo {
+			lo = w
+		}
+		if i == 0 || w > hi {
+			hi = w
+		}
+	}
+	mean := 0.0
+	if queries > 0 {
+		mean = float64(elapsed) / float64(time.Millisecond) / float64(queries)
+	}
+	fmt.Fprintf(bw, "p res sp %s %s\n", kind, opts.Solver)
+	fmt.Fprintf(bw, "f %s %s\n", opts.GraphFile, opts.QueryFile)
+	fmt.Fprintf(bw, "g %d %d %s %s\n", g.NumVertices(), len(weights), common.AppendWeight(nil, lo), common.AppendWeight(nil, hi))
+	fmt.Fprintf(bw, "t %.2f\n", mean)
+}
+
+// checksum sums the distances of the reached vertices among the first n.
+func checksum[W common.Weight](n int, dist func(v int) W) string {
+	if common.IsFloat[W]() {
+		var sum float64
+		for v := 0; v < n; v++ {
+			if d := dist(v); !common.IsInf(d) {
+				sum += float64(d)
+			}
+		}
+		return strconv.FormatFloat(sum, 'g', -1, 64)
+	}
+	var sum uint64
+	for v := 0; v < n; v++ {
+		if d := dist(v); !common.IsInf(d) {
+			sum = (sum + uint64(d)) % checksumModulus
+		}
+	}
+	return strconv.FormatUint(sum, 10)
+}
@@ -0,0 +1,469 @@
+// Package graphbin stores a CSRGraph in a versioned, checksummed binary file that can
+// be memory-mapped read-only, so a large graph is ready for queries without parsing
+// and several processes share one copy in the page cache.
+//
+// A file is a 64-byte header followed by the CSR arrays, all little-endian:
+//
+//	offset  size  field
+//	0       8     magic "BMSSPCSR"
+//	8       2     format version, currently 1
+//	10      2     flags; bit 0 marks a directed graph
+//	12      1     weight type, see WeightType
+//	13      1     weight size in bytes
+//	14      2     reserved, zero
+//	16      8     N, the number of vertices
+//	24      8     M, the number of edges
+//	32      8     CRC-64 (ECMA) of the header with this field zeroed and the payload
+//	40      24    reserved, zero
+//
+// The payload holds N+1 uint64 offsets, M uint32 edge heads, zero padding up to a
+// multiple of 8 bytes and M weights, so every array is aligned to its element size.
+package graphbin
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"math"
+	"os"
+	"playground/common"
+	"unsafe"
+)
+
+// Version is the format version written by Write.
+const Version = 1
+
+const (
+	headerSize   = 64
+	flagDirected = 1 << 0
+)
+
+var magic = [8]byte{'B', 'M', 'S', 'S', 'P', 'C', 'S', 'R'}
+
+var crcTable = crc64.MakeTable(crc64.ECMA)
+
+var (
+	// ErrBadMagic is reported for files that do not start with the graphbin magic.
+	ErrBadMagic = errors.New("not a graphbin file")
+	// ErrUnsupportedVersion is reported for files written by a newer format version.
+	ErrUnsupportedVersion = errors.New("unsupported format version")
+	// ErrTruncated is reported when the file is shorter than its header announces.
+	ErrTruncated = errors.New("file is truncated")
+	// ErrChecksum is reported when the stored checksum does not match the contents.
+	ErrChecksum = errors.New("checksum mismatch")
+	// ErrWeightType is reported when a file is opened with a different weight type
+	// than it was written with.
+	ErrWeightType = errors.New("weight type mismatch")
+)
+
+// WeightType identifies the weight type of a file.
+type WeightType uint8
+
+// Weight types, named after the Go type whose values they store.
+const (
+	Int32 WeightType = iota + 1
+	Int64
+	Uint32
+	Float32
+	Float64
+)
+
+var weightTypeNames = map[WeightType]string{
+	Int32: "int32", Int64: "int64", Uint32: "uint32", Float32: "float32", Float64: "float64",
+}
+
+func (t WeightType) String() string {
+	if name, ok := weightTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("WeightType(%d)", uint8(t))
+}
+
+// Size returns the size of one weight in bytes, or 0 for an unknown type.
+func (t WeightType) Size() int {
+	switch t {
+	case Int32, Uint32, Float32:
+		return 4
+	case Int64, Float64:
+		return 8
+	}
+	return 0
+}
+
+// WeightTypeOf returns the WeightType that stores weights of type W.
+func WeightTypeOf[W common.Weight]() WeightType {
+	switch {
+	case common.IsFloat[W]():
+		if common.WeightBits[W]() == 32 {
+			return Float32
+		}
+		return Float64
+	case common.IsUnsigned[W]():
+		return Uint32
+	case common.WeightBits[W]() == 32:
+		return Int32
+	default:
+		return Int64
+	}
+}
+
+// Header describes a file without its payload.
+type Header struct {
+	Version    uint16
+	Directed   bool
+	WeightType WeightType
+	N          uint64
+	M          uint64
+	Checksum   uint64
+}
+
+// payloadSize returns the number of bytes that follow the header.
+func (h *Header) payloadSize() (uint64, bool) {
+	const maxEdges = math.MaxInt64 / 16
+	if h.N > math.MaxInt32 || h.M > maxEdges {
+		return 0, false
+	}
+	return 8*(h.N+1) + weightsStart(h.M) + h.M*uint64(h.WeightType.Size()), true
+}
+
+// weightsStart returns the size of the heads array padded to a multiple of 8 bytes.
+func weightsStart(m uint64) uint64 {
+	return (4*m + 7) &^ 7
+}
+
+func (h *Header) encode(buf []byte) {
+	clear(buf[:headerSize])
+	copy(buf, magic[:])
+	binary.LittleEndian.PutUint16(buf[8:], h.Version)
+	if h.Directed {
+		binary.LittleEndian.PutUint16(buf[10:], flagDirected)
+	}
+	buf[12] = byte(h.WeightType)
+	buf[13] = byte(h.WeightType.Size())
+	binary.LittleEndian.PutUint64(buf[16:], h.N)
+	binary.LittleEndian.PutUint64(buf[24:], h.M)
+	binary.LittleEndian.PutUint64(buf[32:], h.Checksum)
+}
+
+func decodeHeader(buf []byte) (Header, error) {
+	if len(buf) < headerSize {
+		return Header{}, ErrTruncated
+	}
+	if [8]byte(buf[:8]) != magic {
+		return Header{}, ErrBadMagic
+	}
+	h := Header{
+		Version:    binary.LittleEndian.Uint16(buf[8:]),
+		Directed:   binary.LittleEndian.Uint16(buf[10:])&flagDirected != 0,
+		WeightType: WeightType(buf[12]),
+		N:          binary.LittleEndian.Uint64(buf[16:]),
+		M:          binary.LittleEndian.Uint64(buf[24:]),
+		Checksum:   binary.LittleEndian.Uint64(buf[32:]),
+	}
+	if h.Version == 0 || h.Version > Version {
+		return Header{}, fmt.Errorf("%w: %d", ErrUnsupportedVersion, h.Version)
+	}
+	if h.WeightType.Size() == 0 || int(buf[13]) != h.WeightType.Size() {
+		return Header{}, fmt.Errorf("%w: unknown weight type %d of size %d", ErrWeightType, buf[12], buf[13])
+	}
+	return h, nil
+}
+
+// ReadHeader reads and checks the header at the start of r, which tells the weight
+// type to open a file with.
+func ReadHeader(r io.Reader) (Header, error) {
+	var buf [headerSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			err = ErrTruncated
+		}
+		return Header{}, fmt.Errorf("graphbin: %w", err)
+	}
+	h, err := decodeHeader(buf[:])
+	if err != nil {
+		return Header{}, fmt.Errorf("graphbin: %w", err)
+	}
+	return h, nil
+}
+
+// Write encodes g into w. directed is recorded in the header, since a CSRGraph does
+// not know whether its edges come in symmetric pairs.
+func Write[W common.Weight](w io.Writer, g *common.CSRGraph[W], directed bool) error {
+	h := Header{
+		Version:    Version,
+		Directed:   directed,
+		WeightType: WeightTypeOf[W](),
+		N:          uint64(g.NumVertices()),
+		M:          uint64(g.NumEdges()),
+	}
+	var buf [headerSize]byte
+	h.encode(buf[:])
+	crc := crc64.New(crcTable)
+	crc.Write(buf[:])
+	cw := bufio.NewWriterSize(crc, 1<<16)
+	if err := writePayload(cw, g, h.WeightType); err != nil {
+		return fmt.Errorf("graphbin: %w", err)
+	}
+	cw.Flush()
+	h.Checksum = crc.Sum64()
+	h.encode(buf[:])
+
+	bw := bufio.NewWriterSize(w, 1<<16)
+	if _, err := bw.Write(buf[:]); err != nil {
+		return fmt.Errorf("graphbin: %w", err)
+	}
+	if err := writePayload(bw, g, h.WeightType); err != nil {
+		return fmt.Errorf("graphbin: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("graphbin: %w", err)
+	}
+	return nil
+}
+
+// WriteFile writes g to the file at path, replacing it if it exists.
+func WriteFile[W common.Weight](path string, g *common.CSRGraph[W], directed bool) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("graphbin: %w", err)
+	}
+	if err := Write(f, g, directed); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("graphbin: %w", err)
+	}
+	return nil
+}
+
+// writePayload encodes the CSR arrays of g. It is called twice by Write, once to
+// compute the checksum and once to produce the output.
+func writePayload[W common.Weight](w io.Writer, g *common.CSRGraph[W], t WeightType) error {
+	offsets, targets, weights := g.Arrays()
+	if len(offsets) == 0 {
+		offsets = []int{0}
+	}
+	var buf [8]byte
+	for _, o := range offsets {
+		binary.LittleEndian.PutUint64(buf[:], uint64(o))
+		if _, err := w.Write(buf[:]); err != nil {
+			return err
+		}
+	}
+	for _, v := range targets {
+		binary.LittleEndian.PutUint32(buf[:], uint32(v))
+		if _, err := w.Write(buf[:4]); err != nil {
+			return err
+		}
+	}
+	if pad := weightsStart(uint64(len(targets))) - 4*uint64(len(targets)); pad > 0 {
+		clear(buf[:])
+		if _, err := w.Write(buf[:pad]); err != nil {
+			return err
+		}
+	}
+	size := t.Size()
+	for _, wt := range weights {
+		putWeight(buf[:], wt, t)
+		if _, err := w.Write(buf[:size]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func putWeight[W common.Weight](buf []byte, w W, t WeightType) {
+	switch t {
+	case Int32:
+		binary.LittleEndian.PutUint32(buf, uint32(int32(w)))
+	case Uint32:
+		binary.LittleEndian.PutUint32(buf, uint32(w))
+	case Float32:
+		binary.LittleEndian.PutUint32(buf, math.Float32bits(float32(w)))
+	case Int64:
+		binary.LittleEndian.PutUint64(buf, uint64(int64(w)))
+	case Float64:
+		binary.LittleEndian.PutUint64(buf, math.Float64bits(float64(w)))
+	}
+}
+
+func getWeight[W common.Weight](buf []byte, t WeightType) W {
+	switch t {
+	case Int32:
+		return W(int32(binary.LittleEndian.Uint32(buf)))
+	case Uint32:
+		return W(binary.LittleEndian.Uint32(buf))
+	case Float32:
+		return W(math.Float32frombits(binary.LittleEndian.Uint32(buf)))
+	case Int64:
+		return W(int64(binary.LittleEndian.Uint64(buf)))
+	default:
+		return W(math.Float64frombits(binary.LittleEndian.Uint64(buf)))
+	}
+}
+
+// File is a graph loaded by Open or Read.
+type File[W common.Weight] struct {
+	Header Header
+	Graph  *common.CSRGraph[W]
+	// Mapped is set if Graph aliases a read-only memory mapping of the file rather
+	// than memory owned by the Go heap.
+	Mapped bool
+	unmap  func() error
+}
+
+// Close releases the memory mapping, if any. Graph must not be used afterwards.
+func (f *File[W]) Close() error {
+	if f.unmap == nil {
+		return nil
+	}
+	unmap := f.unmap
+	f.unmap, f.Graph = nil, nil
+	if err := unmap(); err != nil {
+		return fmt.Errorf("graphbin: %w", err)
+	}
+	return nil
+}
+
+// Open maps the file at path read-only and returns its graph, verifying the checksum
+// and the CSR structure first. On Linux the graph aliases the mapping, so it is ready
+// without copying and is shared with other processes mapping the same file; elsewhere,
+// and on big-endian machines, the file is read into memory instead.
+func Open[W common.Weight](path string) (*File[W], error) {
+	return open[W](path, true)
+}
+
+// OpenSkipChecksum is like Open but does not verify the checksum, which saves a pass
+// over the whole file. The CSR structure and the weights are still checked, so a
+// corrupted file can produce wrong distances but no out-of-range access.
+func OpenSkipChecksum[W common.Weight](path string) (*File[W], error) {
+	return open[W](path, false)
+}
+
+func open[W common.Weight](path string, verify bool) (*File[W], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("graphbin: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("graphbin: %w", err)
+	}
+	if info.Size() < headerSize {
+		return nil, fmt.Errorf("graphbin: %w", ErrTruncated)
+	}
+
+	data, unmap, err := mapFile(f, int(info.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("graphbin: %w", err)
+	}
+	file, err := decode[W](data, verify)
+	if err != nil {
+		if unmap != nil {
+			unmap()
+		}
+		return nil, fmt.Errorf("graphbin: %w", err)
+	}
+	switch {
+	case unmap == nil: // data was read into the Go heap, which keeps it alive.
+		file.Mapped = false
+	case file.Mapped:
+		file.unmap = unmap
+	default: // The arrays were copied out of the mapping.
+		if err := unmap(); err != nil {
+			return nil, fmt.Errorf("graphbin: %w", err)
+		}
+	}
+	return file, nil
+}
+
+// Read reads a whole file from r into memory and decodes it, verifying the checksum.
+func Read[W common.Weight](r io.Reader) (*File[W], error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("graphbin: %w", err)
+	}
+	file, err := decode[W](data, true)
+	if err != nil {
+		return nil, fmt.Errorf("graphbin: %w", err)
+	}
+	// The arrays may alias data, which the Go heap keeps alive; there is nothing to unmap.
+	file.Mapped = false
+	return file, nil
+}
+
+// decode checks data and builds the graph, aliasing data where the host layout allows.
+func decode[W common.Weight](data []byte, verify bool) (*File[W], error) {
+	h, err := decodeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if want := WeightTypeOf[W](); h.WeightType != want {
+		return nil, fmt.Errorf("%w: file holds %v, opened as %v", ErrWeightType, h.WeightType, want)
+	}
+	size, ok := h.payloadSize()
+	if !ok || uint64(len(data)-headerSize) < size {
+		return nil, ErrTruncated
+	}
+	if verify {
+		crc := crc64.New(crcTable)
+		var buf [headerSize]byte
+		copy(buf[:], data)
+		clear(buf[32:40])
+		crc.Write(buf[:])
+		crc.Write(data[headerSize : headerSize+size])
+		if crc.Sum64() != h.Checksum {
+			return nil, ErrChecksum
+		}
+	}
+
+	n, m := int(h.N), int(h.M)
+	offStart := headerSize
+	tgtStart := offStart + 8*(n+1)
+	wStart := tgtStart + int(weightsStart(h.M))
+
+	var (
+		offsets []int
+		targets []int32
+		weights []W
+	)
+	aliased := canAlias(data)
+	if aliased {
+		offsets = unsafe.Slice((*int)(unsafe.Pointer(&data[offStart])), n+1)
+		if m > 0 {
+			targets = unsafe.Slice((*int32)(unsafe.Pointer(&data[tgtStart])), m)
+			weights = unsafe.Slice((*W)(unsafe.Pointer(&data[wStart])), m)
+		}
+	} else {
+		offsets = make([]int, n+1)
+		for i := range offsets {
+			offsets[i] = int(binary.LittleEndian.Uint64(data[offStart+8*i:]))
+		}
+		targets = make([]int32, m)
+		weights = make([]W, m)
+		size := h.WeightType.Size()
+		for i := range targets {
+			targets[i] = int32(binary.LittleEndian.Uint32(data[tgtStart+4*i:]))
+			weights[i] = getWeight[W](data[wStart+size*i:], h.WeightType)
+		}
+	}
+
+	g, err := common.NewCSRGraphFromArrays(offsets, targets, weights)
+	if err != nil {
+		return nil, err
+	}
+	return &File[W]{Header: h, Graph: g, Mapped: aliased}, nil
+}
+
+// canAlias reports whether the arrays in data can be used in place: the host has to be
+// little-endian with 64-bit ints, and data 8-byte aligned.
+func canAlias(data []byte) bool {
+	probe := uint16(1)
+	littleEndian := *(*byte)(unsafe.Pointer(&probe)) == 1
+	return littleEndian && unsafe.Sizeof(int(0)) == 8 && uintptr(unsafe.Pointer(&data[0]))%8 == 0
+}
@@ -0,0 +1,156 @@
+package graphbin
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"playground/bmssp"
+	"playground/common"
+	"playground/internal/graphtest"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestOpen_RoundTrip(t *testing.T) {
+	g := graphtest.RandomFractional[float64](500, 2000, true, 1)
+	csr, err := common.NewCSRGraph(g)
+	if err != nil {
+		t.Fatalf("NewCSRGraph() returned an error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "g.bin")
+	if err := WriteFile(path, csr, true); err != nil {
+		t.Fatalf("WriteFile() returned an error: %v", err)
+	}
+
+	f, err := Open[float64](path)
+	if err != nil {
+		t.Fatalf("Open() returned an error: %v", err)
+	}
+	defer f.Close()
+	if f.Header.N != 500 || f.Header.M != 2000 || !f.Header.Directed || f.Header.WeightType != Float64 {
+		t.Errorf("unexpected header %+v", f.Header)
+	}
+	if runtime.GOOS == "linux" && !f.Mapped {
+		t.Error("expected the graph to alias the memory mapping on Linux")
+	}
+	assertSameCSR(t, csr, f.Graph)
+
+	want, err := bmssp.SolveSSSPCSR(csr, []int{0})
+	if err != nil {
+		t.Fatalf("SolveSSSPCSR() returned an error: %v", err)
+	}
+	got, err := bmssp.SolveSSSPCSR(f.Graph, []int{0})
+	if err != nil {
+		t.Fatalf("SolveSSSPCSR() returned an error: %v", err)
+	}
+	if !slices.Equal(got.Distances(), want.Distances()) {
+		t.Error("distances on the opened graph differ from the original")
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() returned an error: %v", err)
+	}
+	if f.Graph != nil {
+		t.Error("expected Close to drop the graph")
+	}
+}
+
+func TestRead_WeightTypes(t *testing.T) {
+	testRoundTrip[int32](t)
+	testRoundTrip[int64](t)
+	testRoundTrip[uint32](t)
+	testRoundTrip[float32](t)
+
+	// An odd edge count exercises the padding between heads and weights.
+	testRoundTripOf(t, graphtest.RandomFractional[int64](7, 3, true, 2))
+	testRoundTripOf(t, &common.Graph[float64]{})
+}
+
+func TestRead_Corruption(t *testing.T) {
+	csr, err := common.NewCSRGraph(graphtest.RandomFractional[float64](50, 200, true, 3))
+	if err != nil {
+		t.Fatalf("NewCSRGraph() returned an error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, csr, false); err != nil {
+		t.Fatalf("Write() returned an error: %v", err)
+	}
+	data := buf.Bytes()
+
+	flipped := slices.Clone(data)
+	flipped[len(flipped)-3] ^= 0x10
+	if _, err := Read[float64](bytes.NewReader(flipped)); !errors.Is(err, ErrChecksum) {
+		t.Errorf("expected ErrChecksum, got %v", err)
+	}
+	if _, err := Read[float64](bytes.NewReader(data[:len(data)-1])); !errors.Is(err, ErrTruncated) {
+		t.Errorf("expected ErrTruncated, got %v", err)
+	}
+	if _, err := Read[int64](bytes.NewReader(data)); !errors.Is(err, ErrWeightType) {
+		t.Errorf("expected ErrWeightType, got %v", err)
+	}
+	if _, err := Read[float64](bytes.NewReader([]byte("not a graph file, but long enough to hold a header of 64 bytes..."))); !errors.Is(err, ErrBadMagic) {
+		t.Errorf("expected ErrBadMagic, got %v", err)
+	}
+	newer := slices.Clone(data)
+	newer[8] = Version + 1
+	if _, err := Read[float64](bytes.NewReader(newer)); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("expected ErrUnsupportedVersion, got %v", err)
+	}
+
+	// A head out of range is caught by the structure check even without the checksum.
+	path := filepath.Join(t.TempDir(), "bad.bin")
+	badHead := slices.Clone(data)
+	copy(badHead[headerSize+8*51:], []byte{0xff, 0xff, 0x00, 0x00})
+	if err := os.WriteFile(path, badHead, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenSkipChecksum[float64](path); !errors.Is(err, common.ErrVertexOutOfRange) {
+		t.Errorf("expected ErrVertexOutOfRange, got %v", err)
+	}
+
+	h, err := ReadHeader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadHeader() returned an error: %v", err)
+	}
+	if h.Directed || h.N != 50 || h.M != 200 {
+		t.Errorf("unexpected header %+v", h)
+	}
+}
+
+// --- Helper Functions ---
+
+func testRoundTrip[W common.Weight](t *testing.T) {
+	t.Helper()
+	testRoundTripOf(t, graphtest.RandomFractional[W](100, 400, true, 4))
+}
+
+func testRoundTripOf[W common.Weight](t *testing.T, g *common.Graph[W]) {
+	t.Helper()
+	csr, err := common.NewCSRGraph(g)
+	if err != nil {
+		t.Fatalf("NewCSRGraph() returned an error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, csr, true); err != nil {
+		t.Fatalf("Write() returned an error: %v", err)
+	}
+	f, err := Read[W](&buf)
+	if err != nil {
+		t.Fatalf("%v: Read() returned an error: %v", WeightTypeOf[W](), err)
+	}
+	assertSameCSR(t, csr, f.Graph)
+}
+
+func assertSameCSR[W common.Weight](t *testing.T, want, got *common.CSRGraph[W]) {
+	t.Helper()
+	wantOff, wantHeads, wantWeights := want.Arrays()
+	gotOff, gotHeads, gotWeights := got.Arrays()
+	if want.NumVertices() != got.NumVertices() || !slices.Equal(wantHeads, gotHeads) || !slices.Equal(wantWeights, gotWeights) {
+		t.Fatalf("%v: decoded graph differs from the original", WeightTypeOf[W]())
+	}
+	if len(wantOff) > 0 && !slices.Equal(wantOff, gotOff) {
+		t.Fatalf("%v: decoded offsets differ from the original", WeightTypeOf[W]())
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package graphbin
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile reads f into memory on platforms where the file is not memory-mapped. The
+// returned unmap function is nil, since there is no mapping to release.
+func mapFile(f *os.File, size int) ([]byte, func() error, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(f, data); err != nil {
+		return nil, nil, err
+	}
+	return data, nil, nil
+}
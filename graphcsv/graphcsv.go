@@ -0,0 +1,228 @@
+// Package graphcsv imports graphs from CSV and TSV edge lists, one edge per record:
+//
+//	from,to,cost
+//	amsterdam,berlin,6.5
+//	berlin,prague,4
+//
+// Records are streamed, so the input is never held in memory, and every row that
+// cannot be turned into an edge is reported as a RowError carrying its line number.
+package graphcsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"playground/common"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrMissingField is reported for records too short to hold a mapped column.
+	ErrMissingField = errors.New("missing field")
+	// ErrInvalidField is reported for vertex or weight fields that cannot be parsed.
+	ErrInvalidField = errors.New("invalid field")
+	// ErrUnknownColumn is reported for column names that the header does not contain.
+	ErrUnknownColumn = errors.New("unknown column")
+)
+
+// RowError reports a record that could not be imported.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error { return e.Err }
+
+// Column selects a field of every record, by header name or by 0-based index.
+type Column struct {
+	Name  string // Looked up in the header; takes precedence over Index if set.
+	Index int
+}
+
+// Options configures an import.
+type Options struct {
+	// Comma separates the fields; ',' if zero. Use '\t' for TSV.
+	Comma rune
+	// Comment starts lines that are skipped; none if zero.
+	Comment rune
+	// Header marks the first record as column names, which Columns can refer to.
+	Header bool
+	// Source, Target and Weight map the columns of an edge. DefaultOptions maps them
+	// to the first three fields.
+	Source, Target, Weight Column
+	// Unweighted ignores Weight and gives every edge weight 1.
+	Unweighted bool
+	// Undirected adds every edge in both directions.
+	Undirected bool
+	// Duplicates selects how parallel edges are treated, see GraphBuilder.WithDuplicates.
+	Duplicates common.DuplicatePolicy
+}
+
+// DefaultOptions returns the options for a headerless CSV file with the fields
+// source, target and weight.
+func DefaultOptions() Options {
+	return Options{Comma: ',', Source: Column{Index: 0}, Target: Column{Index: 1}, Weight: Column{Index: 2}}
+}
+
+// Read imports a graph whose vertices are named by arbitrary labels. Labels are
+// interned in order of first appearance, so the first vertex named gets id 0.
+func Read[W common.Weight](r io.Reader, opts Options) (*common.LabeledGraph[string, W], error) {
+	labels := common.NewLabels[string]()
+	b := common.NewGraphBuilder[W](!opts.Undirected).WithDuplicates(opts.Duplicates)
+	intern := func(s string) (int, error) {
+		if s == "" {
+			return 0, fmt.Errorf("%w: empty vertex label", ErrInvalidField)
+		}
+		if id, ok := labels.ID(s); ok {
+			return id, nil
+		}
+		// Fields share their memory with the whole record; keep only the label.
+		return labels.Intern(strings.Clone(s)), nil
+	}
+	if err := scan(r, opts, intern, b); err != nil {
+		return nil, err
+	}
+	g, err := b.WithVertices(labels.Len()).Build()
+	if err != nil {
+		return nil, fmt.Errorf("graphcsv: %w", err)
+	}
+	return &common.LabeledGraph[string, W]{Graph: g, Labels: labels}, nil
+}
+
+// ReadIDs imports a graph whose vertices are given as 0-based integer ids. The graph
+// has one vertex more than the largest id, so ids must lie below math.MaxInt32.
+func ReadIDs[W common.Weight](r io.Reader, opts Options) (*common.Graph[W], error) {
+	b := common.NewGraphBuilder[W](!opts.Undirected).WithDuplicates(opts.Duplicates)
+	parse := func(s string) (int, error) {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("%w: vertex id %q", ErrInvalidField, s)
+		}
+		if v < 0 || v >= math.MaxInt32 {
+			return 0, &common.VertexError{Vertex: v, Err: common.ErrVertexOutOfRange}
+		}
+		return v, nil
+	}
+	if err := scan(r, opts, parse, b); err != nil {
+		return nil, err
+	}
+	g, err := b.Build()
+	if err != nil {
+		return nil, fmt.Errorf("graphcsv: %w", err)
+	}
+	return g, nil
+}
+
+// scan reads the records of r and adds an edge to b for each of them, turning vertex
+// fields into ids with vertex.
+func scan[W common.Weight](r io.Reader, opts Options, vertex func(string) (int, error), b *common.GraphBuilder[W]) error {
+	cr := csv.NewReader(r)
+	cr.Comma = opts.Comma
+	if cr.Comma == 0 {
+		cr.Comma = ','
+	}
+	cr.Comment = opts.Comment
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	cols := []Column{opts.Source, opts.Target}
+	if !opts.Unweighted {
+		cols = append(cols, opts.Weight)
+	}
+	index := make([]int, len(cols))
+	var header []string
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// csv.ParseError already names the line.
+			return fmt.Errorf("graphcsv: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		if first {
+			if opts.Header {
+				header = record
+			}
+			if err := resolve(cols, header, index); err != nil {
+				return fmt.Errorf("graphcsv: %w", &RowError{Line: line, Err: err})
+			}
+			if opts.Header {
+				continue
+			}
+		}
+		if err := addRecord(record, index, opts.Unweighted, vertex, b); err != nil {
+			return fmt.Errorf("graphcsv: %w", &RowError{Line: line, Err: err})
+		}
+	}
+	return nil
+}
+
+// resolve stores the field index of every column in index, looking names up in header.
+func resolve(cols []Column, header []string, index []int) error {
+	for i, c := range cols {
+		index[i] = c.Index
+		if c.Name == "" {
+			if c.Index < 0 {
+				return &common.ParameterError{Name: "column", Value: c.Index, Reason: "must be non-negative"}
+			}
+			continue
+		}
+		index[i] = -1
+		for j, h := range header {
+			if strings.TrimSpace(h) == c.Name {
+				index[i] = j
+				break
+			}
+		}
+		if index[i] < 0 {
+			if header == nil {
+				return &common.ParameterError{Name: "column", Value: c.Name, Reason: "names need a header"}
+			}
+			return fmt.Errorf("%w: %q", ErrUnknownColumn, c.Name)
+		}
+	}
+	return nil
+}
+
+func addRecord[W common.Weight](record []string, index []int, unweighted bool, vertex func(string) (int, error), b *common.GraphBuilder[W]) error {
+	field := func(i int) (string, error) {
+		if index[i] >= len(record) {
+			return "", fmt.Errorf("%w: record has %d fields, column %d is mapped", ErrMissingField, len(record), index[i])
+		}
+		return strings.TrimSpace(record[index[i]]), nil
+	}
+	var ends [2]int
+	for i := range ends {
+		s, err := field(i)
+		if err != nil {
+			return err
+		}
+		if ends[i], err = vertex(s); err != nil {
+			return err
+		}
+	}
+	w := W(1)
+	if !unweighted {
+		s, err := field(2)
+		if err != nil {
+			return err
+		}
+		if w, err = common.ParseWeight[W](s); err != nil {
+			return fmt.Errorf("%w: weight %q", ErrInvalidField, s)
+		}
+		if err := common.CheckWeight(w); err != nil {
+			return &common.EdgeError[W]{Edge: common.Edge[W]{U: ends[0], V: ends[1], Weight: w}, Err: err}
+		}
+	}
+	b.AddEdge(ends[0], ends[1], w)
+	return nil
+}
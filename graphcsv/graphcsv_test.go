@@ -0,0 +1,153 @@
+package graphcsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"playground/bmssp"
+	"playground/common"
+	"strings"
+	"testing"
+)
+
+func TestRead_HeaderAndColumns(t *testing.T) {
+	input := `id,cost,to,from
+1,6.5,berlin,amsterdam
+2,4,prague,berlin
+3,  1.5 ,"Den Haag, NL",amsterdam
+`
+	opts := DefaultOptions()
+	opts.Header = true
+	opts.Source, opts.Target, opts.Weight = Column{Name: "from"}, Column{Name: "to"}, Column{Name: "cost"}
+	g, err := Read[float64](strings.NewReader(input), opts)
+	if err != nil {
+		t.Fatalf("Read() returned an error: %v", err)
+	}
+	if g.N != 4 || len(g.Edges) != 3 || !g.Directed {
+		t.Fatalf("unexpected graph: N=%d, %d edges", g.N, len(g.Edges))
+	}
+	if g.Labels.Key(0) != "amsterdam" || g.Labels.Key(3) != "Den Haag, NL" {
+		t.Errorf("expected labels in order of first appearance, got %q and %q", g.Labels.Key(0), g.Labels.Key(3))
+	}
+
+	res, err := bmssp.SolveSSSPLabeled(g, []string{"amsterdam"})
+	if err != nil {
+		t.Fatalf("SolveSSSPLabeled() returned an error: %v", err)
+	}
+	if d := res.DistOf("prague"); d != 10.5 {
+		t.Errorf("expected distance 10.5 to prague, got %v", d)
+	}
+}
+
+func TestRead_TSVCommentsUndirected(t *testing.T) {
+	input := "# exported edges\n3\t7\t2\n# another comment\n7\t9\t5\n"
+	opts := DefaultOptions()
+	opts.Comma, opts.Comment, opts.Undirected = '\t', '#', true
+	g, err := ReadIDs[int64](strings.NewReader(input), opts)
+	if err != nil {
+		t.Fatalf("ReadIDs() returned an error: %v", err)
+	}
+	if g.N != 10 || g.Directed || len(g.Edges) != 4 {
+		t.Fatalf("unexpected graph: N=%d, Directed=%v, %d edges", g.N, g.Directed, len(g.Edges))
+	}
+	res, err := bmssp.SolveSSSP(g, []int{9})
+	if err != nil {
+		t.Fatalf("SolveSSSP() returned an error: %v", err)
+	}
+	if d := res.Dist(3); d != 7 {
+		t.Errorf("expected distance 7 from 9 to 3 along undirected edges, got %v", d)
+	}
+
+	opts.Unweighted = true
+	g, err = ReadIDs[int64](strings.NewReader("0\t1\n1\t2\n"), opts)
+	if err != nil {
+		t.Fatalf("ReadIDs() returned an error: %v", err)
+	}
+	if g.Edges[0].Weight != 1 {
+		t.Errorf("expected unit weights, got %v", g.Edges[0].Weight)
+	}
+}
+
+func TestRead_RowErrors(t *testing.T) {
+	header := DefaultOptions()
+	header.Header = true
+	header.Source, header.Target, header.Weight = Column{Name: "from"}, Column{Name: "to"}, Column{Name: "cost"}
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		line  int
+		want  error
+	}{
+		{"short record", "a,b,1\nc,d\n", DefaultOptions(), 2, ErrMissingField},
+		{"bad weight", "a,b,1\n\na,c,heavy\n", DefaultOptions(), 3, ErrInvalidField},
+		{"negative weight", "a,b,-2\n", DefaultOptions(), 1, common.ErrNegativeWeight},
+		{"infinite weight", "a,b,1\na,c,+Inf\n", DefaultOptions(), 2, common.ErrInvalidWeight},
+		{"empty label", "a,,1\n", DefaultOptions(), 1, ErrInvalidField},
+		{"unknown column", "from,to,weight\na,b,1\n", header, 1, ErrUnknownColumn},
+		{"names without header", "a,b,1\n", Options{Source: Column{Name: "from"}}, 1, common.ErrInvalidParameter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read[float64](strings.NewReader(tt.input), tt.opts)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			var re *RowError
+			if !errors.As(err, &re) || re.Line != tt.line {
+				t.Errorf("expected a RowError on line %d, got %v", tt.line, err)
+			}
+		})
+	}
+
+	if _, err := ReadIDs[float64](strings.NewReader("0,1,1\n-4,1,1\n"), DefaultOptions()); !errors.Is(err, common.ErrVertexOutOfRange) {
+		t.Errorf("expected ErrVertexOutOfRange for a negative id, got %v", err)
+	}
+	_, err := ReadIDs[float64](strings.NewReader("0,1,1\n0,9223372036854775806,1\n"), DefaultOptions())
+	var re *RowError
+	if !errors.Is(err, common.ErrVertexOutOfRange) || !errors.As(err, &re) || re.Line != 2 {
+		t.Errorf("expected ErrVertexOutOfRange on line 2 for an id beyond int32, got %v", err)
+	}
+	if _, err := ReadIDs[float64](strings.NewReader("0,1,1\n\"2,1,1\n"), DefaultOptions()); !errors.As(err, new(*csv.ParseError)) {
+		t.Errorf("expected a csv.ParseError for an unterminated quote, got %v", err)
+	}
+}
+
+func TestReadIDs_Streaming(t *testing.T) {
+	const n = 20000
+	opts := DefaultOptions()
+	opts.Header = true
+	g, err := ReadIDs[uint32](newChainReader(n), opts)
+	if err != nil {
+		t.Fatalf("ReadIDs() returned an error: %v", err)
+	}
+	if g.N != n || len(g.Edges) != n-1 {
+		t.Fatalf("expected a chain of %d vertices, got N=%d with %d edges", n, g.N, len(g.Edges))
+	}
+}
+
+// --- Helper Functions ---
+
+// chainReader generates the CSV of a chain graph on the fly.
+type chainReader struct {
+	n, next int
+	pending []byte
+}
+
+func newChainReader(n int) *chainReader {
+	return &chainReader{n: n, pending: []byte("from,to,weight\n")}
+}
+
+func (c *chainReader) Read(p []byte) (int, error) {
+	for len(c.pending) < len(p) && c.next < c.n-1 {
+		c.pending = fmt.Appendf(c.pending, "%d,%d,%d\n", c.next, c.next+1, c.next%7+1)
+		c.next++
+	}
+	if len(c.pending) == 0 {
+		return 0, io.EOF
+	}
+	k := copy(p, c.pending)
+	c.pending = c.pending[k:]
+	return k, nil
+}
@@ -0,0 +1,92 @@
+package graphjson
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/bytedance/sonic"
+	gojson "github.com/goccy/go-json"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// ErrUnknownCodec is reported by CodecByName for names that no codec answers to.
+var ErrUnknownCodec = errors.New("unknown codec")
+
+// Codec is a JSON library. Every codec reads and writes the same documents; they only
+// differ in speed and allocations.
+type Codec interface {
+	Name() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, v any) error
+}
+
+var (
+	// Std uses encoding/json. It is the codec used when nil is passed.
+	Std Codec = stdCodec{}
+	// Sonic uses github.com/bytedance/sonic in its standard-library compatible mode.
+	Sonic Codec = sonicCodec{}
+	// GoJSON uses github.com/goccy/go-json.
+	GoJSON Codec = goJSONCodec{}
+	// Jsoniter uses github.com/json-iterator/go in its standard-library compatible mode.
+	Jsoniter Codec = jsoniterCodec{}
+)
+
+// Codecs lists every available codec, Std first.
+func Codecs() []Codec {
+	return []Codec{Std, Sonic, GoJSON, Jsoniter}
+}
+
+// CodecByName returns the codec whose Name is name.
+func CodecByName(name string) (Codec, error) {
+	for _, c := range Codecs() {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("graphjson: %w: %q", ErrUnknownCodec, name)
+}
+
+func orStd(c Codec) Codec {
+	if c == nil {
+		return Std
+	}
+	return c
+}
+
+type stdCodec struct{}
+
+func (stdCodec) Name() string                       { return "encoding/json" }
+func (stdCodec) Marshal(v any) ([]byte, error)      { return stdjson.Marshal(v) }
+func (stdCodec) Unmarshal(data []byte, v any) error { return stdjson.Unmarshal(data, v) }
+func (stdCodec) Encode(w io.Writer, v any) error    { return stdjson.NewEncoder(w).Encode(v) }
+func (stdCodec) Decode(r io.Reader, v any) error    { return stdjson.NewDecoder(r).Decode(v) }
+
+type sonicCodec struct{}
+
+func (sonicCodec) Name() string                       { return "sonic" }
+func (sonicCodec) Marshal(v any) ([]byte, error)      { return sonic.ConfigStd.Marshal(v) }
+func (sonicCodec) Unmarshal(data []byte, v any) error { return sonic.ConfigStd.Unmarshal(data, v) }
+func (sonicCodec) Encode(w io.Writer, v any) error    { return sonic.ConfigStd.NewEncoder(w).Encode(v) }
+func (sonicCodec) Decode(r io.Reader, v any) error    { return sonic.ConfigStd.NewDecoder(r).Decode(v) }
+
+type goJSONCodec struct{}
+
+func (goJSONCodec) Name() string                       { return "go-json" }
+func (goJSONCodec) Marshal(v any) ([]byte, error)      { return gojson.Marshal(v) }
+func (goJSONCodec) Unmarshal(data []byte, v any) error { return gojson.Unmarshal(data, v) }
+func (goJSONCodec) Encode(w io.Writer, v any) error    { return gojson.NewEncoder(w).Encode(v) }
+func (goJSONCodec) Decode(r io.Reader, v any) error    { return gojson.NewDecoder(r).Decode(v) }
+
+type jsoniterCodec struct{}
+
+var jsoniterStd = jsoniter.ConfigCompatibleWithStandardLibrary
+
+func (jsoniterCodec) Name() string                       { return "jsoniter" }
+func (jsoniterCodec) Marshal(v any) ([]byte, error)      { return jsoniterStd.Marshal(v) }
+func (jsoniterCodec) Unmarshal(data []byte, v any) error { return jsoniterStd.Unmarshal(data, v) }
+func (jsoniterCodec) Encode(w io.Writer, v any) error    { return jsoniterStd.NewEncoder(w).Encode(v) }
+func (jsoniterCodec) Decode(r io.Reader, v any) error    { return jsoniterStd.NewDecoder(r).Decode(v) }
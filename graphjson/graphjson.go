@@ -0,0 +1,246 @@
+// Package graphjson defines JSON documents for graphs, queries and results and
+// encodes them with a choice of JSON libraries, see Codec. Vertices are addressed by
+// their dense ids 0..vertices-1 throughout; labels only name them.
+//
+// A graph document looks like
+//
+//	{"directed": true, "vertices": 3, "labels": ["a", "b", "c"],
+//	 "edges": [{"source": 0, "target": 1, "weight": 2.5}, ...]}
+//
+// and a result document like
+//
+//	{"sources": [0], "distances": [0, 2.5, null],
+//	 "predecessors": [-1, 0, -1], "paths": [{"target": 1, "distance": 2.5, "vertices": [0, 1]}]}
+//
+// where null marks an unreachable vertex.
+package graphjson
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"playground/common"
+)
+
+// Graph is the JSON form of a graph. Undirected graphs list each edge once.
+type Graph[W common.Weight] struct {
+	Directed bool      `json:"directed"`
+	Vertices int       `json:"vertices"`
+	Edges    []Edge[W] `json:"edges"`
+
+	// Labels optionally names every vertex; the names must be distinct.
+	Labels []string `json:"labels,omitempty"`
+	// Attributes holds free-form data about the whole graph.
+	Attributes map[string]any `json:"attributes,omitempty"`
+	// VertexAttributes optionally holds free-form data for every vertex.
+	VertexAttributes []map[string]any `json:"vertex_attributes,omitempty"`
+}
+
+// Edge is the JSON form of an edge.
+type Edge[W common.Weight] struct {
+	Source     int            `json:"source"`
+	Target     int            `json:"target"`
+	Weight     W              `json:"weight"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// FromGraph returns the document for g, listing edges in adjacency order. For an
+// undirected graph only the direction with Source <= Target is listed.
+func FromGraph[W common.Weight](g *common.Graph[W]) *Graph[W] {
+	doc := &Graph[W]{Directed: g.Directed, Vertices: g.N, Edges: make([]Edge[W], 0, len(g.Edges))}
+	for u := 0; u < g.N; u++ {
+		for _, e := range g.Adj[u] {
+			if g.Directed || e.U <= e.V {
+				doc.Edges = append(doc.Edges, Edge[W]{Source: e.U, Target: e.V, Weight: e.Weight})
+			}
+		}
+	}
+	return doc
+}
+
+// FromLabeledGraph is like FromGraph and records the labels of g, formatted with fmt.Sprint.
+func FromLabeledGraph[K comparable, W common.Weight](g *common.LabeledGraph[K, W]) *Graph[W] {
+	doc := FromGraph(g.Graph)
+	doc.Labels = make([]string, g.N)
+	for v := range doc.Labels {
+		doc.Labels[v] = fmt.Sprint(g.Labels.Key(v))
+	}
+	return doc
+}
+
+// Build validates the document and returns its graph. Attributes are not carried over,
+// and the mirrored edges of an undirected graph may appear in a different adjacency
+// order than in the graph the document was made from.
+func (d *Graph[W]) Build() (*common.Graph[W], error) {
+	if d.Labels != nil && len(d.Labels) != d.Vertices {
+		return nil, fmt.Errorf("graphjson: %w", &common.ParameterError{Name: "labels", Value: len(d.Labels), Reason: "must name every vertex"})
+	}
+	if d.VertexAttributes != nil && len(d.VertexAttributes) != d.Vertices {
+		return nil, fmt.Errorf("graphjson: %w", &common.ParameterError{Name: "vertex_attributes", Value: len(d.VertexAttributes), Reason: "must hold one entry per vertex"})
+	}
+	b := common.NewGraphBuilder[W](d.Directed).WithVertices(d.Vertices)
+	for _, e := range d.Edges {
+		b.AddEdge(e.Source, e.Target, e.Weight)
+	}
+	g, err := b.Build()
+	if err != nil {
+		return nil, fmt.Errorf("graphjson: %w", err)
+	}
+	return g, nil
+}
+
+// BuildLabeled is like Build but also requires labels and returns a LabeledGraph.
+// Duplicate labels are reported as a ParameterError.
+func (d *Graph[W]) BuildLabeled() (*common.LabeledGraph[string, W], error) {
+	if d.Labels == nil {
+		return nil, fmt.Errorf("graphjson: %w", &common.ParameterError{Name: "labels", Value: nil, Reason: "are required for a labeled graph"})
+	}
+	g, err := d.Build()
+	if err != nil {
+		return nil, err
+	}
+	labels := common.NewLabels[string]()
+	for v, k := range d.Labels {
+		if labels.Intern(k) != v {
+			return nil, fmt.Errorf("graphjson: %w", &common.ParameterError{Name: "labels", Value: k, Reason: "names more than one vertex"})
+		}
+	}
+	return &common.LabeledGraph[string, W]{Graph: g, Labels: labels}, nil
+}
+
+// Query is the JSON form of a shortest-path query.
+type Query[W common.Weight] struct {
+	Sources []int `json:"sources"`
+	// Targets optionally names the vertices whose paths the result should list.
+	Targets []int `json:"targets,omitempty"`
+	// Bound optionally limits the search to distances below it.
+	Bound *W `json:"bound,omitempty"`
+	// Reverse asks for distances toward the sources instead of from them.
+	Reverse bool `json:"reverse,omitempty"`
+}
+
+// Result is the JSON form of a solver result.
+type Result[W common.Weight] struct {
+	Sources   []int        `json:"sources,omitempty"`
+	Bound     *W           `json:"bound,omitempty"`
+	Reverse   bool         `json:"reverse,omitempty"`
+	Distances Distances[W] `json:"distances"`
+	// Predecessors holds the vertex each vertex was reached from, -1 if none. For a
+	// reverse result it holds the next vertex toward the nearest source instead.
+	Predecessors []int     `json:"predecessors,omitempty"`
+	Paths        []Path[W] `json:"paths,omitempty"`
+}
+
+// Path is the JSON form of a shortest path to a target. Its vertices lead from a
+// source to the target, or from the target to a source for reverse results.
+type Path[W common.Weight] struct {
+	Target   int   `json:"target"`
+	Distance W     `json:"distance"`
+	Vertices []int `json:"vertices"`
+}
+
+// NewResult returns the document for a result r of query q. Predecessors are listed
+// if r has them, and a path is listed for every reachable target of q.
+func NewResult[W common.Weight](q *Query[W], r *common.Result[W]) *Result[W] {
+	doc := &Result[W]{Distances: r.Distances(), Reverse: r.Reverse()}
+	if q != nil {
+		doc.Sources, doc.Bound = q.Sources, q.Bound
+	}
+	if r.HasPredecessors() {
+		doc.Predecessors = make([]int, r.Len())
+		for v := range doc.Predecessors {
+			doc.Predecessors[v] = -1
+			if e, ok := r.Pred(v); ok {
+				doc.Predecessors[v] = e.U
+				if r.Reverse() {
+					doc.Predecessors[v] = e.V
+				}
+			}
+		}
+	}
+	if q != nil {
+		for _, t := range q.Targets {
+			if vertices, _, ok := r.Path(t); ok {
+				doc.Paths = append(doc.Paths, Path[W]{Target: t, Distance: r.Dist(t), Vertices: vertices})
+			}
+		}
+	}
+	return doc
+}
+
+// Distances is a distance array whose unreachable entries, common.Inf, are written
+// as null.
+type Distances[W common.Weight] []W
+
+// MarshalJSON writes the distances as an array of numbers and nulls.
+func (d Distances[W]) MarshalJSON() ([]byte, error) {
+	if d == nil {
+		return []byte("null"), nil
+	}
+	buf := make([]byte, 0, 2+8*len(d))
+	buf = append(buf, '[')
+	for i, w := range d {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		if common.IsInf(w) {
+			buf = append(buf, "null"...)
+		} else {
+			buf = common.AppendWeight(buf, w)
+		}
+	}
+	return append(buf, ']'), nil
+}
+
+// UnmarshalJSON reads an array of numbers and nulls.
+func (d *Distances[W]) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		*d = nil
+		return nil
+	}
+	if len(data) < 2 || data[0] != '[' || data[len(data)-1] != ']' {
+		return fmt.Errorf("graphjson: distances must be an array")
+	}
+	out := (*d)[:0]
+	body := bytes.TrimSpace(data[1 : len(data)-1])
+	for len(body) > 0 {
+		item, rest, found := bytes.Cut(body, []byte{','})
+		item = bytes.TrimSpace(item)
+		if string(item) == "null" {
+			out = append(out, common.Inf[W]())
+		} else {
+			w, err := common.ParseWeight[W](string(item))
+			if err != nil || w != w || w < 0 {
+				return fmt.Errorf("graphjson: invalid distance %q", item)
+			}
+			out = append(out, w)
+		}
+		body = rest
+		if found && len(bytes.TrimSpace(rest)) == 0 {
+			return fmt.Errorf("graphjson: trailing comma in distances")
+		}
+	}
+	if out == nil {
+		out = Distances[W]{}
+	}
+	*d = out
+	return nil
+}
+
+// EncodeGraph writes g as a graph document with c, Std if c is nil.
+func EncodeGraph[W common.Weight](c Codec, w io.Writer, g *common.Graph[W]) error {
+	if err := orStd(c).Encode(w, FromGraph(g)); err != nil {
+		return fmt.Errorf("graphjson: %w", err)
+	}
+	return nil
+}
+
+// DecodeGraph reads a graph document with c, Std if c is nil, and builds its graph.
+func DecodeGraph[W common.Weight](c Codec, r io.Reader) (*common.Graph[W], error) {
+	var doc Graph[W]
+	if err := orStd(c).Decode(r, &doc); err != nil {
+		return nil, fmt.Errorf("graphjson: %w", err)
+	}
+	return doc.Build()
+}
@@ -0,0 +1,156 @@
+package graphjson
+
+import (
+	"bytes"
+	"errors"
+	"playground/common"
+	"playground/dijkstra"
+	"playground/internal/graphtest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGraph_RoundTrip(t *testing.T) {
+	directed := graphtest.RandomFractional[float64](200, 800, true, 1)
+	undirected := graphtest.RandomFractional[int64](100, 300, false, 2)
+	for _, c := range Codecs() {
+		t.Run(c.Name(), func(t *testing.T) {
+			testGraphRoundTrip(t, c, directed)
+			testGraphRoundTrip(t, c, undirected)
+
+			// Large integer weights must survive without a detour through float64.
+			g := graphtest.MustBuild(common.NewGraphBuilder[int64](true).AddEdge(0, 1, 1<<60+1))
+			testGraphRoundTrip(t, c, g)
+		})
+	}
+}
+
+func TestGraph_LabelsAndAttributes(t *testing.T) {
+	lg, err := common.NewLabeledGraphBuilder[string, float64](true).
+		AddEdge("amsterdam", "berlin", 6.5).
+		AddEdge("berlin", "prague", 4).
+		AddVertex("oslo").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() returned an error: %v", err)
+	}
+	doc := FromLabeledGraph(lg)
+	doc.Attributes = map[string]any{"name": "rail", "year": 2024.0}
+	doc.VertexAttributes = []map[string]any{{"capital": true}, {"capital": true}, nil, {"capital": true}}
+	doc.Edges[0].Attributes = map[string]any{"operator": "NS"}
+
+	for _, c := range Codecs() {
+		data, err := c.Marshal(doc)
+		if err != nil {
+			t.Fatalf("%s: Marshal() returned an error: %v", c.Name(), err)
+		}
+		var got Graph[float64]
+		if err := c.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%s: Unmarshal() returned an error: %v", c.Name(), err)
+		}
+		if !reflect.DeepEqual(&got, doc) {
+			t.Errorf("%s: document differs after a round trip:\n%s", c.Name(), data)
+		}
+		back, err := got.BuildLabeled()
+		if err != nil {
+			t.Fatalf("%s: BuildLabeled() returned an error: %v", c.Name(), err)
+		}
+		if id, ok := back.Labels.ID("oslo"); !ok || id != 3 || len(back.Adj[id]) != 0 {
+			t.Errorf("%s: expected the isolated vertex oslo to keep id 3", c.Name())
+		}
+	}
+
+	doc.Labels[3] = "berlin"
+	if _, err := doc.BuildLabeled(); !errors.Is(err, common.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for a duplicate label, got %v", err)
+	}
+	doc.Labels = doc.Labels[:2]
+	if _, err := doc.Build(); !errors.Is(err, common.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for missing labels, got %v", err)
+	}
+}
+
+func TestGraph_Invalid(t *testing.T) {
+	input := `{"directed": true, "vertices": 2, "edges": [{"source": 0, "target": 2, "weight": 1}]}`
+	for _, c := range Codecs() {
+		if _, err := DecodeGraph[float64](c, strings.NewReader(input)); !errors.Is(err, common.ErrVertexOutOfRange) {
+			t.Errorf("%s: expected ErrVertexOutOfRange, got %v", c.Name(), err)
+		}
+		if _, err := DecodeGraph[float64](c, strings.NewReader(`{"vertices": "two"}`)); err == nil {
+			t.Errorf("%s: expected an error for a malformed document", c.Name())
+		}
+	}
+	if _, err := CodecByName("xml"); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("expected ErrUnknownCodec, got %v", err)
+	}
+}
+
+func TestResult_RoundTrip(t *testing.T) {
+	g := graphtest.MustBuild(common.NewGraphBuilder[float64](true).WithVertices(5).
+		AddEdge(0, 1, 1.5).AddEdge(1, 2, 2).AddEdge(0, 2, 4).AddEdge(3, 0, 1))
+	bound := 10.0
+	q := &Query[float64]{Sources: []int{0}, Targets: []int{2, 3}, Bound: &bound}
+	res, err := dijkstra.NewDijkstraAlgorithm(g, q.Sources, q.Bound).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	doc := NewResult(q, res)
+	if !slices.Equal(doc.Predecessors, []int{-1, 0, 1, -1, -1}) {
+		t.Errorf("unexpected predecessors %v", doc.Predecessors)
+	}
+	if len(doc.Paths) != 1 || !slices.Equal(doc.Paths[0].Vertices, []int{0, 1, 2}) || doc.Paths[0].Distance != 3.5 {
+		t.Errorf("expected only the path 0 -> 1 -> 2 of length 3.5, got %+v", doc.Paths)
+	}
+
+	for _, c := range Codecs() {
+		data, err := c.Marshal(doc)
+		if err != nil {
+			t.Fatalf("%s: Marshal() returned an error: %v", c.Name(), err)
+		}
+		if !bytes.Contains(data, []byte(`"distances":[0,1.5,3.5,null,null]`)) {
+			t.Errorf("%s: expected unreachable vertices as null, got %s", c.Name(), data)
+		}
+		var got Result[float64]
+		if err := c.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%s: Unmarshal() returned an error: %v", c.Name(), err)
+		}
+		if !reflect.DeepEqual(&got, doc) {
+			t.Errorf("%s: result differs after a round trip: %s", c.Name(), data)
+		}
+
+		var query Query[float64]
+		if err := c.Unmarshal([]byte(`{"sources": [1, 4], "bound": 2.5, "reverse": true}`), &query); err != nil {
+			t.Fatalf("%s: Unmarshal() returned an error: %v", c.Name(), err)
+		}
+		if !slices.Equal(query.Sources, []int{1, 4}) || query.Bound == nil || *query.Bound != 2.5 || !query.Reverse {
+			t.Errorf("%s: unexpected query %+v", c.Name(), query)
+		}
+	}
+
+	var d Distances[uint32]
+	if err := d.UnmarshalJSON([]byte(`[1, -2]`)); err == nil {
+		t.Error("expected an error for a negative distance")
+	}
+	if err := d.UnmarshalJSON([]byte(` [ 7 , null ] `)); err != nil || !slices.Equal(d, Distances[uint32]{7, common.Inf[uint32]()}) {
+		t.Errorf("expected [7 Inf], got %v (%v)", d, err)
+	}
+}
+
+// --- Helper Functions ---
+
+func testGraphRoundTrip[W common.Weight](t *testing.T, c Codec, g *common.Graph[W]) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := EncodeGraph(c, &buf, g); err != nil {
+		t.Fatalf("EncodeGraph() returned an error: %v", err)
+	}
+	got, err := DecodeGraph[W](c, &buf)
+	if err != nil {
+		t.Fatalf("DecodeGraph() returned an error: %v", err)
+	}
+	if got.N != g.N || got.Directed != g.Directed || !slices.Equal(graphtest.SortedEdges(got), graphtest.SortedEdges(g)) {
+		t.Fatalf("graph differs after a round trip")
+	}
+}
@@ -0,0 +1,166 @@
+package graphstats
+
+import (
+	"playground/common"
+	"playground/dijkstra"
+)
+
+// ConnectedComponents returns the weakly connected component of every vertex and the
+// number of components. Edge directions are ignored; components are numbered in the
+// order of their smallest vertex.
+func ConnectedComponents[W common.Weight](g *common.CSRGraph[W]) ([]int, int) {
+	n := g.NumVertices()
+	parent := make([]int, n)
+	for v := range parent {
+		parent[v] = v
+	}
+	find := func(v int) int {
+		for parent[v] != v {
+			parent[v] = parent[parent[v]]
+			v = parent[v]
+		}
+		return v
+	}
+	for u := 0; u < n; u++ {
+		targets, _ := g.Neighbors(u)
+		for _, v := range targets {
+			if ru, rv := find(u), find(int(v)); ru != rv {
+				parent[max(ru, rv)] = min(ru, rv)
+			}
+		}
+	}
+
+	// Roots are the smallest vertex of their component, so a single pass numbers them.
+	comp := make([]int, n)
+	count := 0
+	for v := 0; v < n; v++ {
+		if r := find(v); r == v {
+			comp[v] = count
+			count++
+		} else {
+			comp[v] = comp[r]
+		}
+	}
+	return comp, count
+}
+
+// StronglyConnectedComponents returns the strongly connected component of every vertex
+// and the number of components, using an iterative version of Tarjan's algorithm.
+// Components are numbered in reverse topological order of the condensation.
+func StronglyConnectedComponents[W common.Weight](g *common.CSRGraph[W]) ([]int, int) {
+	n := g.NumVertices()
+	index := make([]int, n) // DFS preorder number plus one; 0 marks unvisited vertices.
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	comp := make([]int, n)
+	var stack []int
+
+	type frame struct{ v, next int }
+	var calls []frame
+	visit := func(v, i int) {
+		index[v], low[v] = i, i
+		stack = append(stack, v)
+		onStack[v] = true
+		calls = append(calls, frame{v: v})
+	}
+
+	count, preorder := 0, 0
+	for s := 0; s < n; s++ {
+		if index[s] != 0 {
+			continue
+		}
+		preorder++
+		visit(s, preorder)
+		for len(calls) > 0 {
+			f := &calls[len(calls)-1]
+			targets, _ := g.Neighbors(f.v)
+			if f.next < len(targets) {
+				w := int(targets[f.next])
+				f.next++
+				if index[w] == 0 {
+					preorder++
+					visit(w, preorder)
+				} else if onStack[w] {
+					low[f.v] = min(low[f.v], index[w])
+				}
+				continue
+			}
+
+			v := f.v
+			calls = calls[:len(calls)-1]
+			if len(calls) > 0 {
+				p := calls[len(calls)-1].v
+				low[p] = min(low[p], low[v])
+			}
+			if low[v] == index[v] {
+				for {
+					w := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[w] = false
+					comp[w] = count
+					if w == v {
+						break
+					}
+				}
+				count++
+			}
+		}
+	}
+	return comp, count
+}
+
+// hopDiameter runs two breadth-first searches that ignore edge directions, the second
+// from the farthest vertex found by the first, and returns the larger eccentricity.
+func hopDiameter[W common.Weight](g, rev *common.CSRGraph[W], start int) int {
+	dist := make([]int, g.NumVertices())
+	far, _ := bfs(g, rev, start, dist)
+	_, ecc := bfs(g, rev, far, dist)
+	return ecc
+}
+
+// bfs fills dist with hop counts from s, -1 for unreached vertices, and returns the
+// farthest vertex and its distance.
+func bfs[W common.Weight](g, rev *common.CSRGraph[W], s int, dist []int) (int, int) {
+	for v := range dist {
+		dist[v] = -1
+	}
+	dist[s] = 0
+	queue := []int{s}
+	far := s
+	for i := 0; i < len(queue); i++ {
+		u := queue[i]
+		far = u
+		out, _ := g.Neighbors(u)
+		in, _ := rev.Neighbors(u)
+		for _, nbrs := range [2][]int32{out, in} {
+			for _, v := range nbrs {
+				if dist[v] < 0 {
+					dist[v] = dist[u] + 1
+					queue = append(queue, int(v))
+				}
+			}
+		}
+	}
+	return far, dist[far]
+}
+
+// weightedDiameter runs Dijkstra from start and again from the farthest vertex it
+// reached, and returns the largest finite distance seen.
+func weightedDiameter[W common.Weight](g *common.CSRGraph[W], start int) W {
+	ws := dijkstra.NewWorkspaceCSR(g)
+	var best W
+	for range 2 {
+		if err := ws.Query([]int{start}, common.Inf[W]()); err != nil {
+			return best
+		}
+		far := start
+		for v := 0; v < g.NumVertices(); v++ {
+			if d := ws.Dist(v); !common.IsInf(d) && d > ws.Dist(far) {
+				far = v
+			}
+		}
+		best = max(best, ws.Dist(far))
+		start = far
+	}
+	return best
+}
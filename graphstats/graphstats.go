@@ -0,0 +1,202 @@
+// Package graphstats summarizes the structure of a graph: degree and weight
+// distributions, connected and strongly connected components and an estimate of the
+// diameter. The numbers are meant to guide the choice between BMSSP and Dijkstra and
+// of BMSSP's parameters.
+package graphstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/bits"
+	"playground/common"
+	"slices"
+)
+
+// Stats describes a graph. Every field is computed by Compute.
+type Stats[W common.Weight] struct {
+	Vertices int  `json:"vertices"`
+	Edges    int  `json:"edges"`
+	Directed bool `json:"directed"`
+
+	OutDegree DegreeStats    `json:"out_degree"`
+	InDegree  DegreeStats    `json:"in_degree"`
+	Weights   WeightStats[W] `json:"weights"`
+
+	// Components are the weakly connected components, which for an undirected graph
+	// are its connected components.
+	Components       ComponentStats `json:"components"`
+	StrongComponents ComponentStats `json:"strong_components"`
+
+	Diameter DiameterEstimate[W] `json:"diameter"`
+}
+
+// DegreeStats summarizes a degree distribution.
+type DegreeStats struct {
+	Min  int     `json:"min"`
+	Max  int     `json:"max"`
+	Mean float64 `json:"mean"`
+	P50  int     `json:"p50"`
+	P90  int     `json:"p90"`
+	P99  int     `json:"p99"`
+	// Histogram[0] counts vertices of degree 0 and Histogram[i] those with a degree
+	// in [2^(i-1), 2^i).
+	Histogram []int `json:"histogram"`
+}
+
+// WeightStats summarizes the edge weights. All fields are zero for a graph without edges.
+type WeightStats[W common.Weight] struct {
+	Min   W       `json:"min"`
+	Max   W       `json:"max"`
+	Mean  float64 `json:"mean"`
+	P50   W       `json:"p50"`
+	P90   W       `json:"p90"`
+	P99   W       `json:"p99"`
+	Zeros int     `json:"zeros"` // Number of zero-weight edges, which form ties in the pull loop.
+}
+
+// ComponentStats summarizes a partition of the vertices into components.
+type ComponentStats struct {
+	Count      int `json:"count"`
+	Largest    int `json:"largest"`    // Size of the largest component.
+	Singletons int `json:"singletons"` // Number of components with a single vertex.
+}
+
+// DiameterEstimate holds lower bounds on the diameter found by double sweeps from a
+// vertex of the largest weakly connected component. Hops ignores edge directions and
+// weights; Weighted is the largest finite shortest-path distance seen.
+type DiameterEstimate[W common.Weight] struct {
+	Hops     int `json:"hops"`
+	Weighted W   `json:"weighted"`
+}
+
+// Compute validates g and computes its statistics.
+func Compute[W common.Weight](g *common.Graph[W]) (*Stats[W], error) {
+	csr, err := common.NewCSRGraph(g)
+	if err != nil {
+		return nil, fmt.Errorf("graphstats: %w", err)
+	}
+	s := ComputeCSR(csr)
+	s.Directed = g.Directed
+	return s, nil
+}
+
+// ComputeCSR computes the statistics of g. Directed is always set, since a CSRGraph
+// does not record whether its edges come in symmetric pairs.
+func ComputeCSR[W common.Weight](g *common.CSRGraph[W]) *Stats[W] {
+	n := g.NumVertices()
+	rev := g.Transpose()
+	s := &Stats[W]{Vertices: n, Edges: g.NumEdges(), Directed: true}
+
+	out := make([]int, n)
+	in := make([]int, n)
+	for v := 0; v < n; v++ {
+		out[v], in[v] = g.Degree(v), rev.Degree(v)
+	}
+	s.OutDegree, s.InDegree = degreeStats(out), degreeStats(in)
+	s.Weights = weightStats(g)
+
+	wcc, count := ConnectedComponents(g)
+	s.Components = componentStats(wcc, count)
+	scc, count := StronglyConnectedComponents(g)
+	s.StrongComponents = componentStats(scc, count)
+
+	if n > 0 {
+		start := slices.Index(wcc, largestComponent(wcc, s.Components.Count))
+		s.Diameter.Hops = hopDiameter(g, rev, start)
+		s.Diameter.Weighted = weightedDiameter(g, start)
+	}
+	return s
+}
+
+// JSON returns the statistics as indented JSON.
+func (s *Stats[W]) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func degreeStats(deg []int) DegreeStats {
+	if len(deg) == 0 {
+		return DegreeStats{}
+	}
+	sorted := slices.Clone(deg)
+	slices.Sort(sorted)
+	ds := DegreeStats{
+		Min: sorted[0],
+		Max: sorted[len(sorted)-1],
+		P50: percentile(sorted, 0.5),
+		P90: percentile(sorted, 0.9),
+		P99: percentile(sorted, 0.99),
+	}
+	sum := 0
+	ds.Histogram = make([]int, bits.Len(uint(ds.Max))+1)
+	for _, d := range deg {
+		sum += d
+		ds.Histogram[bits.Len(uint(d))]++
+	}
+	ds.Mean = float64(sum) / float64(len(deg))
+	return ds
+}
+
+func weightStats[W common.Weight](g *common.CSRGraph[W]) WeightStats[W] {
+	var weights []W
+	for u := 0; u < g.NumVertices(); u++ {
+		_, w := g.Neighbors(u)
+		weights = append(weights, w...)
+	}
+	if len(weights) == 0 {
+		return WeightStats[W]{}
+	}
+	slices.Sort(weights)
+	ws := WeightStats[W]{
+		Min: weights[0],
+		Max: weights[len(weights)-1],
+		P50: percentile(weights, 0.5),
+		P90: percentile(weights, 0.9),
+		P99: percentile(weights, 0.99),
+	}
+	sum := 0.0
+	for _, w := range weights {
+		sum += float64(w)
+		if w == 0 {
+			ws.Zeros++
+		}
+	}
+	ws.Mean = sum / float64(len(weights))
+	return ws
+}
+
+// percentile returns the nearest-rank p-quantile of a sorted, non-empty slice.
+func percentile[T int | common.Weight](sorted []T, p float64) T {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
+
+func componentStats(comp []int, count int) ComponentStats {
+	size := make([]int, count)
+	for _, c := range comp {
+		size[c]++
+	}
+	cs := ComponentStats{Count: count}
+	for _, sz := range size {
+		cs.Largest = max(cs.Largest, sz)
+		if sz == 1 {
+			cs.Singletons++
+		}
+	}
+	return cs
+}
+
+// largestComponent returns the id of the largest component, the smallest id on ties.
+func largestComponent(comp []int, count int) int {
+	size := make([]int, count)
+	best := 0
+	for _, c := range comp {
+		size[c]++
+	}
+	for c, sz := range size {
+		if sz > size[best] {
+			best = c
+		}
+	}
+	return best
+}
@@ -0,0 +1,120 @@
+package graphstats
+
+import (
+	"encoding/json"
+	"errors"
+	"playground/common"
+	"playground/internal/graphtest"
+	"slices"
+	"testing"
+)
+
+func TestCompute_SmallDirectedGraph(t *testing.T) {
+	// Two 2-cycles joined by a one-way edge, a tail 4 -> 5 and an isolated vertex 6.
+	g := graphtest.MustBuild(common.NewGraphBuilder[int64](true).WithVertices(7).
+		AddEdge(0, 1, 1).
+		AddEdge(1, 0, 1).
+		AddEdge(1, 2, 5).
+		AddEdge(2, 3, 2).
+		AddEdge(3, 2, 0).
+		AddEdge(3, 4, 4).
+		AddEdge(4, 5, 3))
+
+	s, err := Compute(g)
+	if err != nil {
+		t.Fatalf("Compute() returned an error: %v", err)
+	}
+	if s.Vertices != 7 || s.Edges != 7 || !s.Directed {
+		t.Errorf("expected 7 vertices, 7 edges and a directed graph, got %d, %d, %v", s.Vertices, s.Edges, s.Directed)
+	}
+	if s.OutDegree.Max != 2 || s.OutDegree.Min != 0 || s.OutDegree.Mean != 1 {
+		t.Errorf("unexpected out-degree stats %+v", s.OutDegree)
+	}
+	if !slices.Equal(s.OutDegree.Histogram, []int{2, 3, 2}) {
+		t.Errorf("expected out-degree histogram [2 3 2], got %v", s.OutDegree.Histogram)
+	}
+	if s.Weights.Min != 0 || s.Weights.Max != 5 || s.Weights.Zeros != 1 || s.Weights.P50 != 2 {
+		t.Errorf("unexpected weight stats %+v", s.Weights)
+	}
+	if want := (ComponentStats{Count: 2, Largest: 6, Singletons: 1}); s.Components != want {
+		t.Errorf("expected components %+v, got %+v", want, s.Components)
+	}
+	if want := (ComponentStats{Count: 5, Largest: 2, Singletons: 3}); s.StrongComponents != want {
+		t.Errorf("expected strong components %+v, got %+v", want, s.StrongComponents)
+	}
+	if s.Diameter.Hops != 5 || s.Diameter.Weighted != 15 {
+		t.Errorf("expected diameter estimates 5 hops and weight 15, got %+v", s.Diameter)
+	}
+
+	js, err := s.JSON()
+	if err != nil {
+		t.Fatalf("JSON() returned an error: %v", err)
+	}
+	var decoded Stats[int64]
+	if err := json.Unmarshal(js, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() returned an error: %v", err)
+	}
+	if decoded.StrongComponents != s.StrongComponents || decoded.Weights != s.Weights {
+		t.Errorf("JSON round trip changed the stats: %s", js)
+	}
+}
+
+func TestStronglyConnectedComponents_MatchesReachability(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		g := graphtest.Random[float64](60, 90, seed)
+		csr, err := common.NewCSRGraph(g)
+		if err != nil {
+			t.Fatalf("NewCSRGraph() returned an error: %v", err)
+		}
+		comp, _ := StronglyConnectedComponents(csr)
+
+		reach := make([][]bool, g.N)
+		for s := range reach {
+			reach[s] = reachable(csr, s)
+		}
+		for u := 0; u < g.N; u++ {
+			for v := 0; v < g.N; v++ {
+				same := reach[u][v] && reach[v][u]
+				if same != (comp[u] == comp[v]) {
+					t.Fatalf("seed %d: vertices %d and %d: mutual reachability %v, same component %v",
+						seed, u, v, same, comp[u] == comp[v])
+				}
+			}
+		}
+	}
+}
+
+func TestCompute_EmptyAndInvalid(t *testing.T) {
+	s, err := Compute(&common.Graph[float64]{})
+	if err != nil {
+		t.Fatalf("Compute() returned an error: %v", err)
+	}
+	if s.Vertices != 0 || s.Components.Count != 0 || s.Diameter.Hops != 0 {
+		t.Errorf("expected empty stats, got %+v", s)
+	}
+
+	bad := &common.Graph[float64]{N: 2, Adj: map[int][]common.Edge[float64]{0: {{U: 0, V: 1, Weight: -1}}}}
+	if _, err := Compute(bad); !errors.Is(err, common.ErrNegativeWeight) {
+		t.Errorf("expected ErrNegativeWeight, got %v", err)
+	}
+}
+
+// --- Helper Functions ---
+
+func reachable(g *common.CSRGraph[float64], s int) []bool {
+	seen := make([]bool, g.NumVertices())
+	seen[s] = true
+	stack := []int{s}
+	for len(stack) > 0 {
+		u := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		targets, _ := g.Neighbors(u)
+		for _, v := range targets {
+			if !seen[v] {
+				seen[v] = true
+				stack = append(stack, int(v))
+			}
+		}
+	}
+	return seen
+}
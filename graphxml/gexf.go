@@ -0,0 +1,223 @@
+package graphxml
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"playground/common"
+)
+
+type gexfAttribute struct {
+	ID      string  `xml:"id,attr"`
+	Title   string  `xml:"title,attr"`
+	Default *string `xml:"default"`
+}
+
+type gexfNode struct {
+	ID    string     `xml:"id,attr"`
+	Nodes []struct{} `xml:"nodes"`
+}
+
+type gexfEdge struct {
+	Source    string  `xml:"source,attr"`
+	Target    string  `xml:"target,attr"`
+	Type      string  `xml:"type,attr"`
+	Weight    *string `xml:"weight,attr"`
+	AttValues []struct {
+		For   string `xml:"for,attr"`
+		Value string `xml:"value,attr"`
+	} `xml:"attvalues>attvalue"`
+}
+
+// ReadGEXF reads a GEXF document. The weight is taken from the edge attribute titled
+// opts.WeightAttribute or, for the default name "weight", from the native weight of
+// the edge. A graph whose defaultedgetype is mixed is read as directed, with
+// undirected and mutual edges added in both directions. Node ids are interned in
+// order of first appearance; hierarchical nodes are not supported.
+func ReadGEXF[W common.Weight](r io.Reader, opts Options) (*common.LabeledGraph[string, W], error) {
+	d := xml.NewDecoder(r)
+	fail := func(err error) (*common.LabeledGraph[string, W], error) {
+		return nil, fmt.Errorf("graphxml: %w", parseError(d, err))
+	}
+	var (
+		b             *common.GraphBuilder[W]
+		directed      bool
+		ns            = newNodeSet()
+		class         string
+		weightAttr    string
+		defaultWeight = W(1)
+	)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail(fmt.Errorf("%w: %v", ErrSyntax, err))
+		}
+		el, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch el.Name.Local {
+		case "graph":
+			if b != nil {
+				return fail(fmt.Errorf("%w: more than one graph", ErrUnsupported))
+			}
+			def, _ := attr(el, "defaultedgetype")
+			switch def {
+			case "directed", "mixed":
+				directed = true
+			case "undirected", "":
+			default:
+				return fail(fmt.Errorf("%w: defaultedgetype %q", ErrSyntax, def))
+			}
+			b = common.NewGraphBuilder[W](directed)
+		case "attributes":
+			class, _ = attr(el, "class")
+		case "attribute":
+			var a gexfAttribute
+			if err := d.DecodeElement(&a, &el); err != nil {
+				return fail(fmt.Errorf("%w: %v", ErrSyntax, err))
+			}
+			if class != "edge" || a.Title != opts.weight() {
+				continue
+			}
+			weightAttr = a.ID
+			if a.Default != nil {
+				if defaultWeight, err = parseWeight[W](*a.Default); err != nil {
+					return fail(err)
+				}
+			}
+		case "node":
+			var n gexfNode
+			if err := d.DecodeElement(&n, &el); err != nil {
+				return fail(fmt.Errorf("%w: %v", ErrSyntax, err))
+			}
+			if n.ID == "" {
+				return fail(fmt.Errorf("%w: node without id", ErrSyntax))
+			}
+			if len(n.Nodes) > 0 {
+				return fail(fmt.Errorf("%w: nested nodes in node %q", ErrUnsupported, n.ID))
+			}
+			if err := ns.declare(n.ID); err != nil {
+				return fail(err)
+			}
+		case "edge":
+			if b == nil {
+				return fail(fmt.Errorf("%w: edge outside a graph", ErrSyntax))
+			}
+			var e gexfEdge
+			if err := d.DecodeElement(&e, &el); err != nil {
+				return fail(fmt.Errorf("%w: %v", ErrSyntax, err))
+			}
+			if e.Source == "" || e.Target == "" {
+				return fail(fmt.Errorf("%w: edge without source or target", ErrSyntax))
+			}
+			w := defaultWeight
+			value, found := "", false
+			for _, av := range e.AttValues {
+				if weightAttr != "" && av.For == weightAttr {
+					value, found = av.Value, true
+				}
+			}
+			if !found && e.Weight != nil && opts.weight() == "weight" {
+				value, found = *e.Weight, true
+			}
+			if found {
+				if w, err = parseWeight[W](value); err != nil {
+					return fail(err)
+				}
+			}
+			u, v := ns.ref(e.Source), ns.ref(e.Target)
+			switch {
+			case (e.Type == "undirected" || e.Type == "mutual") && directed:
+				b.AddUndirectedEdge(u, v, w)
+			case e.Type == "directed" && !directed:
+				return fail(fmt.Errorf("%w: directed edge %q -> %q in an undirected graph", ErrUnsupported, e.Source, e.Target))
+			case e.Type != "" && e.Type != "directed" && e.Type != "undirected" && e.Type != "mutual":
+				return fail(fmt.Errorf("%w: edge type %q", ErrSyntax, e.Type))
+			default:
+				b.AddEdge(u, v, w)
+			}
+		}
+	}
+	if b == nil {
+		return fail(fmt.Errorf("%w: no graph element", ErrSyntax))
+	}
+	g, err := b.WithVertices(ns.labels.Len()).Build()
+	if err != nil {
+		return nil, fmt.Errorf("graphxml: %w", err)
+	}
+	return &common.LabeledGraph[string, W]{Graph: g, Labels: ns.labels}, nil
+}
+
+// WriteGEXF writes g as a GEXF 1.3 document, naming nodes with opts.NodeID. Weights
+// are written as the native edge weight, or as an edge attribute if
+// opts.WeightAttribute names another one. If res is not nil, its distances and
+// predecessors are written as node attributes. An undirected graph lists each edge
+// once.
+func WriteGEXF[W common.Weight](w io.Writer, g *common.Graph[W], res *common.Result[W], opts Options) error {
+	if err := checkResult(res, g.N); err != nil {
+		return fmt.Errorf("graphxml: %w", err)
+	}
+	bw := bufio.NewWriter(w)
+	edgeType := "undirected"
+	if g.Directed {
+		edgeType = "directed"
+	}
+	native := opts.weight() == "weight"
+	fmt.Fprintf(bw, "%s<gexf xmlns=\"http://gexf.net/1.3\" version=\"1.3\">\n", xml.Header)
+	fmt.Fprintf(bw, "  <graph defaultedgetype=\"%s\" mode=\"static\">\n", edgeType)
+	if res != nil {
+		bw.WriteString("    <attributes class=\"node\">\n")
+		fmt.Fprintf(bw, "      <attribute id=\"0\" title=\"%s\" type=\"%s\"/>\n", escape(opts.distance()), weightType[W](true))
+		fmt.Fprintf(bw, "      <attribute id=\"1\" title=\"%s\" type=\"string\"/>\n", escape(opts.predecessor()))
+		bw.WriteString("    </attributes>\n")
+	}
+	if !native {
+		bw.WriteString("    <attributes class=\"edge\">\n")
+		fmt.Fprintf(bw, "      <attribute id=\"0\" title=\"%s\" type=\"%s\"/>\n", escape(opts.weight()), weightType[W](true))
+		bw.WriteString("    </attributes>\n")
+	}
+	bw.WriteString("    <nodes>\n")
+	for v := 0; v < g.N; v++ {
+		id := escape(opts.nodeID(v))
+		fmt.Fprintf(bw, "      <node id=\"%s\" label=\"%s\"", id, id)
+		var dist, pred string
+		if res != nil {
+			dist, pred = resultValues(res, v, opts)
+		}
+		if dist == "" {
+			bw.WriteString("/>\n")
+			continue
+		}
+		fmt.Fprintf(bw, "><attvalues><attvalue for=\"0\" value=\"%s\"/>", dist)
+		if pred != "" {
+			fmt.Fprintf(bw, "<attvalue for=\"1\" value=\"%s\"/>", escape(pred))
+		}
+		bw.WriteString("</attvalues></node>\n")
+	}
+	bw.WriteString("    </nodes>\n    <edges>\n")
+	id := 0
+	for u := 0; u < g.N; u++ {
+		for _, e := range g.Adj[u] {
+			if !g.Directed && e.U > e.V {
+				continue
+			}
+			fmt.Fprintf(bw, "      <edge id=\"%d\" source=\"%s\" target=\"%s\"", id, escape(opts.nodeID(e.U)), escape(opts.nodeID(e.V)))
+			if native {
+				fmt.Fprintf(bw, " weight=\"%s\"/>\n", common.AppendWeight(nil, e.Weight))
+			} else {
+				fmt.Fprintf(bw, "><attvalues><attvalue for=\"0\" value=\"%s\"/></attvalues></edge>\n", common.AppendWeight(nil, e.Weight))
+			}
+			id++
+		}
+	}
+	bw.WriteString("    </edges>\n  </graph>\n</gexf>\n")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("graphxml: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package graphxml
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"playground/common"
+)
+
+type graphmlKey struct {
+	ID      string  `xml:"id,attr"`
+	For     string  `xml:"for,attr"`
+	Name    string  `xml:"attr.name,attr"`
+	Default *string `xml:"default"`
+}
+
+type graphmlNode struct {
+	ID     string     `xml:"id,attr"`
+	Graphs []struct{} `xml:"graph"`
+}
+
+type graphmlEdge struct {
+	Source   string `xml:"source,attr"`
+	Target   string `xml:"target,attr"`
+	Directed string `xml:"directed,attr"`
+	Data     []struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"data"`
+}
+
+// ReadGraphML reads a GraphML document. The weight is taken from the edge data whose
+// key has attr.name, or else id, opts.WeightAttribute. Edges follow the edgedefault of
+// the graph; an edge marked directed="false" in a directed graph is added in both
+// directions. Node ids are interned in order of first appearance, so edges may refer
+// to nodes declared later.
+func ReadGraphML[W common.Weight](r io.Reader, opts Options) (*common.LabeledGraph[string, W], error) {
+	d := xml.NewDecoder(r)
+	fail := func(err error) (*common.LabeledGraph[string, W], error) {
+		return nil, fmt.Errorf("graphxml: %w", parseError(d, err))
+	}
+	var (
+		b             *common.GraphBuilder[W]
+		directed      bool
+		ns            = newNodeSet()
+		weightKey     string
+		defaultWeight = W(1)
+	)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail(fmt.Errorf("%w: %v", ErrSyntax, err))
+		}
+		el, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch el.Name.Local {
+		case "key":
+			var k graphmlKey
+			if err := d.DecodeElement(&k, &el); err != nil {
+				return fail(fmt.Errorf("%w: %v", ErrSyntax, err))
+			}
+			if k.For != "edge" && k.For != "all" || (k.Name != opts.weight() && (k.Name != "" || k.ID != opts.weight())) {
+				continue
+			}
+			weightKey = k.ID
+			if k.Default != nil {
+				if defaultWeight, err = parseWeight[W](*k.Default); err != nil {
+					return fail(err)
+				}
+			}
+		case "graph":
+			if b != nil {
+				return fail(fmt.Errorf("%w: more than one graph", ErrUnsupported))
+			}
+			def, _ := attr(el, "edgedefault")
+			switch def {
+			case "directed", "":
+				directed = true
+			case "undirected":
+			default:
+				return fail(fmt.Errorf("%w: edgedefault %q", ErrSyntax, def))
+			}
+			b = common.NewGraphBuilder[W](directed)
+		case "node":
+			var n graphmlNode
+			if err := d.DecodeElement(&n, &el); err != nil {
+				return fail(fmt.Errorf("%w: %v", ErrSyntax, err))
+			}
+			if n.ID == "" {
+				return fail(fmt.Errorf("%w: node without id", ErrSyntax))
+			}
+			if len(n.Graphs) > 0 {
+				return fail(fmt.Errorf("%w: nested graph in node %q", ErrUnsupported, n.ID))
+			}
+			if err := ns.declare(n.ID); err != nil {
+				return fail(err)
+			}
+		case "edge":
+			if b == nil {
+				return fail(fmt.Errorf("%w: edge outside a graph", ErrSyntax))
+			}
+			var e graphmlEdge
+			if err := d.DecodeElement(&e, &el); err != nil {
+				return fail(fmt.Errorf("%w: %v", ErrSyntax, err))
+			}
+			if e.Source == "" || e.Target == "" {
+				return fail(fmt.Errorf("%w: edge without source or target", ErrSyntax))
+			}
+			w := defaultWeight
+			for _, data := range e.Data {
+				if weightKey != "" && data.Key == weightKey {
+					if w, err = parseWeight[W](data.Value); err != nil {
+						return fail(err)
+					}
+				}
+			}
+			u, v := ns.ref(e.Source), ns.ref(e.Target)
+			switch {
+			case e.Directed == "false" && directed:
+				b.AddUndirectedEdge(u, v, w)
+			case e.Directed == "true" && !directed:
+				return fail(fmt.Errorf("%w: directed edge %q -> %q in an undirected graph", ErrUnsupported, e.Source, e.Target))
+			default:
+				b.AddEdge(u, v, w)
+			}
+		case "hyperedge":
+			return fail(fmt.Errorf("%w: hyperedge", ErrUnsupported))
+		}
+	}
+	if b == nil {
+		return fail(fmt.Errorf("%w: no graph element", ErrSyntax))
+	}
+	g, err := b.WithVertices(ns.labels.Len()).Build()
+	if err != nil {
+		return nil, fmt.Errorf("graphxml: %w", err)
+	}
+	return &common.LabeledGraph[string, W]{Graph: g, Labels: ns.labels}, nil
+}
+
+// WriteGraphML writes g as a GraphML document, naming nodes with opts.NodeID. If res
+// is not nil, its distances and predecessors are written as node data. An undirected
+// graph lists each edge once.
+func WriteGraphML[W common.Weight](w io.Writer, g *common.Graph[W], res *common.Result[W], opts Options) error {
+	if err := checkResult(res, g.N); err != nil {
+		return fmt.Errorf("graphxml: %w", err)
+	}
+	bw := bufio.NewWriter(w)
+	edgeDefault := "undirected"
+	if g.Directed {
+		edgeDefault = "directed"
+	}
+	fmt.Fprintf(bw, "%s<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\">\n", xml.Header)
+	fmt.Fprintf(bw, "  <key id=\"d0\" for=\"edge\" attr.name=\"%s\" attr.type=\"%s\"/>\n", escape(opts.weight()), weightType[W](false))
+	if res != nil {
+		fmt.Fprintf(bw, "  <key id=\"d1\" for=\"node\" attr.name=\"%s\" attr.type=\"%s\"/>\n", escape(opts.distance()), weightType[W](false))
+		fmt.Fprintf(bw, "  <key id=\"d2\" for=\"node\" attr.name=\"%s\" attr.type=\"string\"/>\n", escape(opts.predecessor()))
+	}
+	fmt.Fprintf(bw, "  <graph id=\"G\" edgedefault=\"%s\">\n", edgeDefault)
+	for v := 0; v < g.N; v++ {
+		fmt.Fprintf(bw, "    <node id=\"%s\"", escape(opts.nodeID(v)))
+		var dist, pred string
+		if res != nil {
+			dist, pred = resultValues(res, v, opts)
+		}
+		if dist == "" {
+			bw.WriteString("/>\n")
+			continue
+		}
+		fmt.Fprintf(bw, "><data key=\"d1\">%s</data>", dist)
+		if pred != "" {
+			fmt.Fprintf(bw, "<data key=\"d2\">%s</data>", escape(pred))
+		}
+		bw.WriteString("</node>\n")
+	}
+	for u := 0; u < g.N; u++ {
+		for _, e := range g.Adj[u] {
+			if !g.Directed && e.U > e.V {
+				continue
+			}
+			fmt.Fprintf(bw, "    <edge source=\"%s\" target=\"%s\"><data key=\"d0\">%s</data></edge>\n",
+				escape(opts.nodeID(e.U)), escape(opts.nodeID(e.V)), common.AppendWeight(nil, e.Weight))
+		}
+	}
+	bw.WriteString("  </graph>\n</graphml>\n")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("graphxml: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// Package graphxml imports and exports graphs in GraphML, as written by yEd, and GEXF,
+// as written by Gephi. Node ids become the labels of a LabeledGraph, and a configurable
+// edge attribute becomes the edge weight. On export, a solver result can be attached
+// as node attributes holding each vertex's distance and predecessor, so that it can be
+// inspected in the same tools.
+package graphxml
+
+import (
+	"cmp"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"playground/common"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrSyntax is reported for documents that are not well-formed or lack required
+	// attributes.
+	ErrSyntax = errors.New("syntax error")
+	// ErrUnsupported is reported for constructs that a Graph cannot hold, such as
+	// hyperedges, nested graphs or directed edges in an undirected graph.
+	ErrUnsupported = errors.New("unsupported construct")
+	// ErrDuplicateNode is reported for node ids declared more than once.
+	ErrDuplicateNode = errors.New("duplicate node id")
+)
+
+// ParseError reports a problem at a line of an input document.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// Options names the attributes that carry weights and results. Empty names select
+// the defaults "weight", "distance" and "predecessor".
+type Options struct {
+	// WeightAttribute is the edge attribute read into and written from Edge.Weight.
+	// Edges without it get weight 1, or the attribute's declared default.
+	WeightAttribute string
+	// DistanceAttribute and PredecessorAttribute are the node attributes a result is
+	// exported to. Unreachable vertices get neither; sources get no predecessor. For a
+	// reverse result the predecessor is the next vertex toward the nearest destination.
+	DistanceAttribute, PredecessorAttribute string
+	// NodeID names vertex v on export; "n<v>" if nil. See NodeIDs.
+	NodeID func(v int) string
+}
+
+func (o Options) weight() string      { return cmp.Or(o.WeightAttribute, "weight") }
+func (o Options) distance() string    { return cmp.Or(o.DistanceAttribute, "distance") }
+func (o Options) predecessor() string { return cmp.Or(o.PredecessorAttribute, "predecessor") }
+
+func (o Options) nodeID(v int) string {
+	if o.NodeID == nil {
+		return "n" + strconv.Itoa(v)
+	}
+	return o.NodeID(v)
+}
+
+// NodeIDs returns a NodeID function that names vertices by their labels, formatted
+// with fmt.Sprint, so that a LabeledGraph read from a file is written back with the
+// same node ids.
+func NodeIDs[K comparable](labels *common.Labels[K]) func(v int) string {
+	return func(v int) string { return fmt.Sprint(labels.Key(v)) }
+}
+
+// nodeSet interns node ids in order of first appearance and remembers which of them
+// were declared by a node element.
+type nodeSet struct {
+	labels   *common.Labels[string]
+	declared []bool
+}
+
+func newNodeSet() *nodeSet {
+	return &nodeSet{labels: common.NewLabels[string]()}
+}
+
+func (ns *nodeSet) ref(id string) int {
+	v := ns.labels.Intern(id)
+	if v == len(ns.declared) {
+		ns.declared = append(ns.declared, false)
+	}
+	return v
+}
+
+func (ns *nodeSet) declare(id string) error {
+	v := ns.ref(id)
+	if ns.declared[v] {
+		return fmt.Errorf("%w: %q", ErrDuplicateNode, id)
+	}
+	ns.declared[v] = true
+	return nil
+}
+
+// parseWeight parses an attribute value into W. Tools write integral weights of
+// double attributes as "2.0", which integer weight types accept.
+func parseWeight[W common.Weight](s string) (W, error) {
+	s = strings.TrimSpace(s)
+	w, err := common.ParseWeight[W](s)
+	if err != nil {
+		x, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil || float64(W(x)) != x {
+			return 0, fmt.Errorf("%w: invalid weight %q", ErrSyntax, s)
+		}
+		w = W(x)
+	}
+	if err := common.CheckWeight(w); err != nil {
+		return 0, fmt.Errorf("%w: weight %q", err, s)
+	}
+	return w, nil
+}
+
+// weightType returns the attribute type that holds W in GraphML or, if gexf is set,
+// in GEXF. Neither format has unsigned types, so uint32 maps to long.
+func weightType[W common.Weight](gexf bool) string {
+	switch {
+	case common.IsFloat[W]() && common.WeightBits[W]() == 32:
+		return "float"
+	case common.IsFloat[W]():
+		return "double"
+	case common.WeightBits[W]() == 32 && !common.IsUnsigned[W]():
+		if gexf {
+			return "integer"
+		}
+		return "int"
+	default:
+		return "long"
+	}
+}
+
+// resultValues returns the distance and predecessor attribute values of v, empty for
+// values that are not exported.
+func resultValues[W common.Weight](res *common.Result[W], v int, opts Options) (dist, pred string) {
+	if !res.Reachable(v) {
+		return "", ""
+	}
+	dist = string(common.AppendWeight(nil, res.Dist(v)))
+	if e, ok := res.Pred(v); ok {
+		p := e.U
+		if res.Reverse() {
+			p = e.V
+		}
+		pred = opts.nodeID(p)
+	}
+	return dist, pred
+}
+
+// checkResult verifies that res, if given, covers the n vertices of a graph.
+func checkResult[W common.Weight](res *common.Result[W], n int) error {
+	if res != nil && res.Len() != n {
+		return &common.ParameterError{Name: "result", Value: res.Len(), Reason: fmt.Sprintf("must cover all %d vertices", n)}
+	}
+	return nil
+}
+
+// attr returns the value of the attribute name of el.
+func attr(el xml.StartElement, name string) (string, bool) {
+	for _, a := range el.Attr {
+		if a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// escape returns s escaped for use in XML text and attribute values.
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// parseError wraps err into a ParseError at the current position of d.
+func parseError(d *xml.Decoder, err error) error {
+	line, _ := d.InputPos()
+	return &ParseError{Line: line, Err: err}
+}
@@ -0,0 +1,258 @@
+package graphxml
+
+import (
+	"bytes"
+	"errors"
+	"playground/common"
+	"playground/dijkstra"
+	"playground/internal/graphtest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// A yEd-style document with the edge weight stored under a custom key name and a
+// default, and an edge that refers to a node declared after it.
+const sampleGraphML = `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="name" attr.type="string"/>
+  <key id="d1" for="edge" attr.name="cost" attr.type="double">
+    <default>5</default>
+  </key>
+  <graph id="G" edgedefault="directed">
+    <node id="a"><data key="d0">Start</data></node>
+    <node id="b"/>
+    <edge source="a" target="b"><data key="d1">2.0</data></edge>
+    <edge source="b" target="c"/>
+    <edge source="a" target="c" directed="false"><data key="d1">9</data></edge>
+    <node id="c"/>
+    <node id="d"/>
+  </graph>
+</graphml>
+`
+
+const sampleGEXF = `<?xml version="1.0" encoding="UTF-8"?>
+<gexf xmlns="http://gexf.net/1.3" version="1.3">
+  <graph defaultedgetype="undirected">
+    <attributes class="edge">
+      <attribute id="0" title="length" type="float"/>
+    </attributes>
+    <nodes>
+      <node id="x" label="X"/>
+      <node id="y" label="Y"/>
+      <node id="z" label="Z"/>
+    </nodes>
+    <edges>
+      <edge id="0" source="x" target="y" weight="1.5"/>
+      <edge id="1" source="y" target="z" weight="4">
+        <attvalues><attvalue for="0" value="0.5"/></attvalues>
+      </edge>
+    </edges>
+  </graph>
+</gexf>
+`
+
+func TestReadGraphML(t *testing.T) {
+	lg, err := ReadGraphML[int64](strings.NewReader(sampleGraphML), Options{WeightAttribute: "cost"})
+	if err != nil {
+		t.Fatalf("ReadGraphML() returned an error: %v", err)
+	}
+	if lg.N != 4 || !lg.Directed || len(lg.Edges) != 4 {
+		t.Fatalf("unexpected graph: N=%d, Directed=%v, %d edges", lg.N, lg.Directed, len(lg.Edges))
+	}
+	for i, want := range []string{"a", "b", "c", "d"} {
+		if got := lg.Labels.Key(i); got != want {
+			t.Errorf("expected label %q for vertex %d, got %q", want, i, got)
+		}
+	}
+	res, err := dijkstra.NewDijkstraAlgorithm(lg.Graph, []int{0}, nil).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	if want := []int64{0, 2, 7, common.Inf[int64]()}; !slices.Equal(res.Distances(), want) {
+		t.Errorf("expected distances %v, got %v", want, res.Distances())
+	}
+
+	// Without the key, every edge gets weight 1.
+	lg, err = ReadGraphML[int64](strings.NewReader(sampleGraphML), Options{})
+	if err != nil {
+		t.Fatalf("ReadGraphML() returned an error: %v", err)
+	}
+	for _, e := range lg.Edges {
+		if e.Weight != 1 {
+			t.Fatalf("expected unit weights, got %v", e)
+		}
+	}
+}
+
+func TestReadGEXF(t *testing.T) {
+	lg, err := ReadGEXF[float64](strings.NewReader(sampleGEXF), Options{})
+	if err != nil {
+		t.Fatalf("ReadGEXF() returned an error: %v", err)
+	}
+	if lg.N != 3 || lg.Directed || len(lg.Edges) != 4 {
+		t.Fatalf("unexpected graph: N=%d, Directed=%v, %d edges", lg.N, lg.Directed, len(lg.Edges))
+	}
+	if d := lg.Result(mustSolve(t, lg.Graph, 0)).DistOf("z"); d != 5.5 {
+		t.Errorf("expected distance 5.5 to z by native weights, got %v", d)
+	}
+
+	lg, err = ReadGEXF[float64](strings.NewReader(sampleGEXF), Options{WeightAttribute: "length"})
+	if err != nil {
+		t.Fatalf("ReadGEXF() returned an error: %v", err)
+	}
+	if d := lg.Result(mustSolve(t, lg.Graph, 0)).DistOf("z"); d != 1.5 {
+		t.Errorf("expected distance 1.5 to z by the length attribute, got %v", d)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	formats := []struct {
+		name  string
+		write func(*bytes.Buffer, *common.Graph[float64], *common.Result[float64], Options) error
+		read  func(*bytes.Buffer, Options) (*common.LabeledGraph[string, float64], error)
+	}{
+		{"GraphML",
+			func(b *bytes.Buffer, g *common.Graph[float64], r *common.Result[float64], o Options) error {
+				return WriteGraphML(b, g, r, o)
+			},
+			func(b *bytes.Buffer, o Options) (*common.LabeledGraph[string, float64], error) {
+				return ReadGraphML[float64](b, o)
+			}},
+		{"GEXF",
+			func(b *bytes.Buffer, g *common.Graph[float64], r *common.Result[float64], o Options) error {
+				return WriteGEXF(b, g, r, o)
+			},
+			func(b *bytes.Buffer, o Options) (*common.LabeledGraph[string, float64], error) {
+				return ReadGEXF[float64](b, o)
+			}},
+	}
+	for _, f := range formats {
+		t.Run(f.name, func(t *testing.T) {
+			for _, directed := range []bool{true, false} {
+				g := graphtest.MustBuild(common.NewGraphBuilder[float64](directed).WithVertices(4).
+					AddEdge(0, 1, 0.25).AddEdge(1, 2, 3).AddEdge(2, 0, 1e-3))
+				labels := common.NewLabels[string]()
+				for _, id := range []string{"A&B", "<c>", "d", "e"} {
+					labels.Intern(id)
+				}
+				opts := Options{WeightAttribute: "cost", NodeID: NodeIDs(labels)}
+				var buf bytes.Buffer
+				if err := f.write(&buf, g, nil, opts); err != nil {
+					t.Fatalf("write returned an error: %v", err)
+				}
+				got, err := f.read(&buf, opts)
+				if err != nil {
+					t.Fatalf("read returned an error: %v", err)
+				}
+				if got.Directed != directed || got.N != g.N || !slices.Equal(graphtest.SortedEdges(got.Graph), graphtest.SortedEdges(g)) {
+					t.Errorf("graph differs after a round trip: %v vs %v", got.Edges, g.Edges)
+				}
+				for v := range g.N {
+					if got.Labels.Key(v) != labels.Key(v) {
+						t.Errorf("expected label %q for vertex %d, got %q", labels.Key(v), v, got.Labels.Key(v))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWriteResult(t *testing.T) {
+	g := graphtest.MustBuild(common.NewGraphBuilder[int32](true).WithVertices(4).
+		AddEdge(0, 1, 2).AddEdge(1, 2, 3).AddEdge(0, 2, 9))
+	res := mustSolve(t, g, 0)
+	opts := Options{DistanceAttribute: "dist", PredecessorAttribute: "parent"}
+
+	var buf bytes.Buffer
+	if err := WriteGraphML(&buf, g, res, opts); err != nil {
+		t.Fatalf("WriteGraphML() returned an error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<key id="d1" for="node" attr.name="dist" attr.type="int"/>`,
+		`<key id="d2" for="node" attr.name="parent" attr.type="string"/>`,
+		`<node id="n0"><data key="d1">0</data></node>`,
+		`<node id="n2"><data key="d1">5</data><data key="d2">n1</data></node>`,
+		`<node id="n3"/>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected GraphML output to contain %s, got:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := WriteGEXF(&buf, g, res, opts); err != nil {
+		t.Fatalf("WriteGEXF() returned an error: %v", err)
+	}
+	out = buf.String()
+	for _, want := range []string{
+		`<attribute id="0" title="dist" type="integer"/>`,
+		`<node id="n2" label="n2"><attvalues><attvalue for="0" value="5"/><attvalue for="1" value="n1"/></attvalues></node>`,
+		`<node id="n3" label="n3"/>`,
+		`<edge id="2" source="n1" target="n2" weight="3"/>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected GEXF output to contain %s, got:\n%s", want, out)
+		}
+	}
+
+	short := common.NewResult(make([]int32, 2), nil)
+	if err := WriteGraphML(&buf, g, short, opts); !errors.Is(err, common.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for a result of the wrong size, got %v", err)
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	const graphml = `<graphml><key id="w" for="edge" attr.name="weight"/><graph edgedefault="undirected">` + "\n"
+	const gexf = `<gexf><graph defaultedgetype="undirected"><nodes>` + "\n"
+	tests := []struct {
+		name  string
+		gexf  bool
+		input string
+		line  int
+		want  error
+	}{
+		{"malformed", false, graphml + "<node id=\"a\">\n</graph>", 3, ErrSyntax},
+		{"no graph", false, "<graphml>\n</graphml>\n", 3, ErrSyntax},
+		{"duplicate node", false, graphml + "<node id=\"a\"/>\n<node id=\"a\"/>\n", 3, ErrDuplicateNode},
+		{"hyperedge", false, graphml + "\n<hyperedge><endpoint node=\"a\"/></hyperedge>\n", 3, ErrUnsupported},
+		{"directed edge", false, graphml + "<edge source=\"a\" target=\"b\" directed=\"true\"/>\n", 2, ErrUnsupported},
+		{"negative weight", false, graphml + "<edge source=\"a\" target=\"b\"><data key=\"w\">-1</data></edge>\n", 2, common.ErrNegativeWeight},
+		{"infinite weight", false, graphml + "<edge source=\"a\" target=\"b\"><data key=\"w\">9223372036854775807</data></edge>\n", 2, common.ErrInvalidWeight},
+		{"fractional weight", false, graphml + "<edge source=\"a\" target=\"b\"><data key=\"w\">0.5</data></edge>\n", 2, ErrSyntax},
+		{"nested graph", false, graphml + "<node id=\"a\"><graph/></node>\n", 2, ErrUnsupported},
+		{"missing target", true, gexf + "</nodes><edges><edge source=\"a\"/>\n", 2, ErrSyntax},
+		{"bad edge type", true, gexf + "</nodes><edges>\n<edge source=\"a\" target=\"b\" type=\"loop\"/>\n", 3, ErrSyntax},
+		{"gexf duplicate node", true, gexf + "<node id=\"a\"/>\n<node id=\"a\"/>\n", 3, ErrDuplicateNode},
+		{"hierarchy", true, gexf + "<node id=\"a\"><nodes><node id=\"b\"/></nodes></node>\n", 2, ErrUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.gexf {
+				_, err = ReadGEXF[int64](strings.NewReader(tt.input), Options{})
+			} else {
+				_, err = ReadGraphML[int64](strings.NewReader(tt.input), Options{})
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			var pe *ParseError
+			if !errors.As(err, &pe) || pe.Line != tt.line {
+				t.Errorf("expected a ParseError on line %d, got %v", tt.line, err)
+			}
+		})
+	}
+}
+
+// --- Helper Functions ---
+
+func mustSolve[W common.Weight](t *testing.T, g *common.Graph[W], source int) *common.Result[W] {
+	t.Helper()
+	res, err := dijkstra.NewDijkstraAlgorithm(g, []int{source}, nil).Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned an error: %v", err)
+	}
+	return res
+}
@@ -0,0 +1,54 @@
+// Package graphtest provides the graph fixtures shared by the tests of the format,
+// statistics and graph-utility packages.
+package graphtest
+
+import (
+	"cmp"
+	"math/rand"
+	"playground/common"
+	"slices"
+)
+
+// MustBuild builds a graph from a fixture's fixed, valid edge list and panics if Build
+// fails.
+func MustBuild[W common.Weight](b *common.GraphBuilder[W]) *common.Graph[W] {
+	g, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// Random returns a directed graph on n vertices with m random edges of integer weight
+// 1 to 10, generated from seed.
+func Random[W common.Weight](n, m int, seed int64) *common.Graph[W] {
+	r := rand.New(rand.NewSource(seed))
+	b := common.NewGraphBuilder[W](true).WithVertices(n)
+	for i := 0; i < m; i++ {
+		b.AddEdge(r.Intn(n), r.Intn(n), W(r.Intn(10)+1))
+	}
+	return MustBuild(b)
+}
+
+// RandomFractional returns a graph on n vertices with m random edges whose weights are
+// multiples of 1/8 from 1 to 125, so that formats have to carry fractions exactly.
+// Integer weight types truncate the fraction.
+func RandomFractional[W common.Weight](n, m int, directed bool, seed int64) *common.Graph[W] {
+	r := rand.New(rand.NewSource(seed))
+	b := common.NewGraphBuilder[W](directed).WithVertices(n)
+	for i := 0; i < m; i++ {
+		b.AddEdge(r.Intn(n), r.Intn(n), W(r.Intn(1000))/W(8)+1)
+	}
+	return MustBuild(b)
+}
+
+// SortedEdges returns the edges of g in a canonical order, for comparing graphs whose
+// adjacency lists may have been reordered, as mirrored undirected edges are by a
+// round trip through most formats.
+func SortedEdges[W common.Weight](g *common.Graph[W]) []common.Edge[W] {
+	edges := slices.Clone(g.Edges)
+	slices.SortFunc(edges, func(a, b common.Edge[W]) int {
+		return cmp.Or(cmp.Compare(a.U, b.U), cmp.Compare(a.V, b.V), cmp.Compare(a.Weight, b.Weight))
+	})
+	return edges
+}
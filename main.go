@@ -8,39 +8,30 @@ import (
 )
 
 func main() {
-	g := &common.Graph{
-		N:   5,
-		Adj: make(map[int][]common.Edge),
-	}
-
-	addUndirected := func(u, v int, w float64) {
-		e1 := common.Edge{U: u, V: v, Weight: w}
-		e2 := common.Edge{U: v, V: u, Weight: w}
-		g.Adj[u] = append(g.Adj[u], e1)
-		g.Adj[v] = append(g.Adj[v], e2)
-		g.Edges = append(g.Edges, e1, e2)
+	g, err := common.NewGraphBuilder[float64](false).
+		AddEdge(0, 1, 1.0).
+		AddEdge(1, 2, 2.0).
+		AddEdge(2, 3, 1.0).
+		AddEdge(3, 4, 3.0).
+		Build()
+	if err != nil {
+		panic(err)
 	}
 
-	addUndirected(0, 1, 1.0)
-	addUndirected(1, 2, 2.0)
-	addUndirected(2, 3, 1.0)
-	addUndirected(3, 4, 3.0)
-
 	S := []int{0} // sources
-	B := 1000.0   // boundary must be float64
-	l := 3        // recursion depth
 
-	algo := bmssp.NewBMSSPAlgorithm(g, l, B, S)
-	dist, err := algo.Solve()
+	// l and B are derived from the graph size; see SSSPResult.
+	res, err := bmssp.SolveSSSP(g, S)
 	if err != nil {
 		panic(err)
 	}
+	fmt.Printf("l=%d k=%d t=%d B=%v\n", res.L, res.K, res.T, res.B)
 
 	for v := 0; v < g.N; v++ {
-		if math.IsInf(dist[v], 1) {
+		if math.IsInf(res.Dist(v), 1) {
 			fmt.Printf("dist[%d] = +Inf\n", v)
 		} else {
-			fmt.Printf("dist[%d] = %.6f\n", v, dist[v])
+			fmt.Printf("dist[%d] = %.6f\n", v, res.Dist(v))
 		}
 	}
 }
@@ -0,0 +1,238 @@
+// Package metis reads and writes the undirected graph format of the METIS and
+// ParMETIS partitioning tools. A .graph file starts with a header line "n m [fmt
+// [ncon]]" and holds one line per vertex listing its neighbors, 1-based, each followed
+// by the weight of the edge if fmt says so. Every edge appears in the lines of both
+// endpoints. Lines starting with '%' are comments; blank lines are vertices without
+// neighbors.
+package metis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"playground/common"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrSyntax is reported for lines that do not follow the format.
+	ErrSyntax = errors.New("syntax error")
+	// ErrCountMismatch is reported when the vertex or edge count differs from the header.
+	ErrCountMismatch = errors.New("count does not match header")
+	// ErrAsymmetric is reported for edges listed by only one of their endpoints, or
+	// with a different weight by each.
+	ErrAsymmetric = errors.New("edge is not listed by both endpoints")
+	// ErrSelfLoop is reported for self-loops, which METIS graphs cannot hold.
+	ErrSelfLoop = errors.New("self-loop")
+)
+
+// ParseError reports a problem on a line of an input file.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// Format is the fmt field of the header: which optional values each vertex line holds.
+type Format struct {
+	VertexSizes   bool // Every line starts with the size of its vertex.
+	VertexWeights int  // Number of vertex weights that follow, 0 if none.
+	EdgeWeights   bool // Every neighbor is followed by the weight of the edge.
+}
+
+// ReadGraph reads a .graph file into an undirected graph. Vertex sizes and weights
+// are skipped; edges get weight 1 if the file holds no edge weights. The format of
+// the file is returned alongside.
+func ReadGraph[W common.Weight](r io.Reader) (*common.Graph[W], Format, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	line := 0
+	errorf := func(err error, format string, args ...any) error {
+		return fmt.Errorf("metis: %w", &ParseError{Line: line, Err: fmt.Errorf("%w: "+format, append([]any{err}, args...)...)})
+	}
+	// next returns the next line that is not a comment.
+	next := func() (string, bool) {
+		for s.Scan() {
+			line++
+			if text := s.Text(); !strings.HasPrefix(text, "%") {
+				return text, true
+			}
+		}
+		return "", false
+	}
+
+	var header []string
+	for {
+		text, ok := next()
+		if !ok {
+			if err := s.Err(); err != nil {
+				return nil, Format{}, fmt.Errorf("metis: %w", err)
+			}
+			return nil, Format{}, errorf(ErrSyntax, "missing header")
+		}
+		if header = strings.Fields(text); len(header) > 0 {
+			break
+		}
+	}
+	n, m, f, err := parseHeader(header)
+	if err != nil {
+		return nil, Format{}, errorf(ErrSyntax, "%v", err)
+	}
+
+	skip := f.VertexWeights
+	if f.VertexSizes {
+		skip++
+	}
+	step := 1
+	if f.EdgeWeights {
+		step = 2
+	}
+	b := common.NewGraphBuilder[W](true).WithVertices(n)
+	arcs := 0
+	for u := 0; u < n; u++ {
+		text, ok := next()
+		if !ok {
+			if err := s.Err(); err != nil {
+				return nil, Format{}, fmt.Errorf("metis: %w", err)
+			}
+			return nil, Format{}, errorf(ErrCountMismatch, "found %d vertex lines, header announced %d", u, n)
+		}
+		fields := strings.Fields(text)
+		if len(fields) < skip || (len(fields)-skip)%step != 0 {
+			return nil, Format{}, errorf(ErrSyntax, "vertex %d: expected %d leading values and %d values per neighbor", u+1, skip, step)
+		}
+		for i := skip; i < len(fields); i += step {
+			v, err := strconv.Atoi(fields[i])
+			if err != nil {
+				return nil, Format{}, errorf(ErrSyntax, "invalid neighbor %q", fields[i])
+			}
+			if v < 1 || v > n {
+				return nil, Format{}, fmt.Errorf("metis: %w", &ParseError{Line: line, Err: &common.VertexError{Vertex: v, Err: common.ErrVertexOutOfRange}})
+			}
+			if v-1 == u {
+				return nil, Format{}, errorf(ErrSelfLoop, "vertex %d lists itself", v)
+			}
+			w := W(1)
+			if f.EdgeWeights {
+				if w, err = common.ParseWeight[W](fields[i+1]); err != nil {
+					return nil, Format{}, errorf(ErrSyntax, "invalid weight %q", fields[i+1])
+				}
+				if err := common.CheckWeight(w); err != nil {
+					return nil, Format{}, fmt.Errorf("metis: %w", &ParseError{Line: line, Err: &common.EdgeError[W]{Edge: common.Edge[W]{U: u, V: v - 1, Weight: w}, Err: err}})
+				}
+			}
+			b.AddEdge(u, v-1, w)
+			arcs++
+		}
+	}
+	for {
+		text, ok := next()
+		if !ok {
+			break
+		}
+		if strings.TrimSpace(text) != "" {
+			return nil, Format{}, errorf(ErrCountMismatch, "more vertex lines than the %d the header announced", n)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, Format{}, fmt.Errorf("metis: %w", err)
+	}
+	if arcs != 2*m {
+		return nil, Format{}, errorf(ErrCountMismatch, "found %d edge entries, header announced %d edges", arcs, m)
+	}
+
+	g, err := b.Build()
+	if err != nil {
+		return nil, Format{}, fmt.Errorf("metis: %w", err)
+	}
+	g.Directed = false
+	for _, is := range common.Check(g).Issues {
+		if is.Kind == common.IssueAsymmetricEdge {
+			return nil, Format{}, fmt.Errorf("metis: %w", &common.EdgeError[W]{Edge: *is.Edge, Err: ErrAsymmetric})
+		}
+	}
+	return g, f, nil
+}
+
+// parseHeader parses the fields "n m [fmt [ncon]]" of the header line.
+func parseHeader(fields []string) (n, m int, f Format, err error) {
+	if len(fields) < 2 || len(fields) > 4 {
+		return 0, 0, f, fmt.Errorf("expected \"n m [fmt [ncon]]\"")
+	}
+	n, errN := strconv.Atoi(fields[0])
+	m, errM := strconv.Atoi(fields[1])
+	if errN != nil || errM != nil || n < 0 || m < 0 {
+		return 0, 0, f, fmt.Errorf("invalid counts %q %q", fields[0], fields[1])
+	}
+	if len(fields) >= 3 {
+		code := fields[2]
+		if len(code) > 3 || strings.Trim(code, "01") != "" {
+			return 0, 0, f, fmt.Errorf("invalid fmt %q", code)
+		}
+		code = strings.Repeat("0", 3-len(code)) + code
+		f.VertexSizes, f.EdgeWeights = code[0] == '1', code[2] == '1'
+		if code[1] == '1' {
+			f.VertexWeights = 1
+		}
+	}
+	if len(fields) == 4 {
+		ncon, err := strconv.Atoi(fields[3])
+		if err != nil || ncon < 1 || f.VertexWeights == 0 {
+			return 0, 0, f, fmt.Errorf("invalid ncon %q", fields[3])
+		}
+		f.VertexWeights = ncon
+	}
+	return n, m, f, nil
+}
+
+// WriteGraph writes the undirected graph g as a .graph file. Edge weights are only
+// written if some edge has a weight other than 1. Directed graphs are rejected, and so
+// are self-loops.
+func WriteGraph[W common.Weight](w io.Writer, g *common.Graph[W]) error {
+	if g.Directed {
+		return fmt.Errorf("metis: %w", &common.ParameterError{Name: "graph", Value: "directed", Reason: "METIS graphs are undirected"})
+	}
+	arcs, weighted := 0, false
+	for u := 0; u < g.N; u++ {
+		for _, e := range g.Adj[u] {
+			if e.U == e.V {
+				return fmt.Errorf("metis: %w", &common.EdgeError[W]{Edge: e, Err: ErrSelfLoop})
+			}
+			arcs++
+			weighted = weighted || e.Weight != 1
+		}
+	}
+	bw := bufio.NewWriter(w)
+	if weighted {
+		fmt.Fprintf(bw, "%d %d 001\n", g.N, arcs/2)
+	} else {
+		fmt.Fprintf(bw, "%d %d\n", g.N, arcs/2)
+	}
+	var buf []byte
+	for u := 0; u < g.N; u++ {
+		buf = buf[:0]
+		for i, e := range g.Adj[u] {
+			if i > 0 {
+				buf = append(buf, ' ')
+			}
+			buf = strconv.AppendInt(buf, int64(e.V+1), 10)
+			if weighted {
+				buf = append(buf, ' ')
+				buf = common.AppendWeight(buf, e.Weight)
+			}
+		}
+		buf = append(buf, '\n')
+		bw.Write(buf)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("metis: %w", err)
+	}
+	return nil
+}
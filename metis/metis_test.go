@@ -0,0 +1,137 @@
+package metis
+
+import (
+	"bytes"
+	"errors"
+	"playground/bmssp"
+	"playground/common"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// The weighted example graph of the METIS manual, with vertex weights dropped.
+const weightedGraph = `% 7 vertices, 11 edges, edge weights
+7 11 001
+5 1 3 2 2 1
+1 1 3 2 4 1
+5 3 4 2 2 2 1 2
+2 1 3 2 6 2 7 5
+1 1 3 3 6 2
+5 2 4 2 7 6
+6 6 4 5
+`
+
+func TestReadGraph(t *testing.T) {
+	g, f, err := ReadGraph[int64](strings.NewReader(weightedGraph))
+	if err != nil {
+		t.Fatalf("ReadGraph() returned an error: %v", err)
+	}
+	if !f.EdgeWeights || f.VertexSizes || f.VertexWeights != 0 {
+		t.Errorf("unexpected format %+v", f)
+	}
+	if g.N != 7 || g.Directed || len(g.Edges) != 22 {
+		t.Fatalf("unexpected graph: N=%d, Directed=%v, %d edges", g.N, g.Directed, len(g.Edges))
+	}
+	res, err := bmssp.SolveSSSP(g, []int{0})
+	if err != nil {
+		t.Fatalf("SolveSSSP() returned an error: %v", err)
+	}
+	if want := []int64{0, 1, 2, 2, 1, 3, 7}; !slices.Equal(res.Distances(), want) {
+		t.Errorf("expected distances %v, got %v", want, res.Distances())
+	}
+
+	// Vertex sizes and two vertex weights per line are skipped; vertex 3 is isolated.
+	withVertexData := "3 1 111 2\n9 1 1 2 4\n8 2 2 1 4\n1 1 1\n"
+	g, f, err = ReadGraph[int64](strings.NewReader(withVertexData))
+	if err != nil {
+		t.Fatalf("ReadGraph() returned an error: %v", err)
+	}
+	if f != (Format{VertexSizes: true, VertexWeights: 2, EdgeWeights: true}) || g.N != 3 || len(g.Edges) != 2 || g.Edges[0].Weight != 4 {
+		t.Errorf("unexpected format %+v or graph %v", f, g.Edges)
+	}
+
+	g, _, err = ReadGraph[int64](strings.NewReader("3 2\n2\n1 3\n2\n"))
+	if err != nil {
+		t.Fatalf("ReadGraph() returned an error: %v", err)
+	}
+	if len(g.Edges) != 4 || g.Edges[0].Weight != 1 {
+		t.Errorf("expected an unweighted path with unit weights, got %v", g.Edges)
+	}
+}
+
+func TestReadGraph_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  int
+		want  error
+	}{
+		{"bad header", "% c\nthree 2\n", 2, ErrSyntax},
+		{"bad fmt", "2 1 201\n2\n1\n", 1, ErrSyntax},
+		{"missing vertex line", "3 1\n2\n1\n", 3, ErrCountMismatch},
+		{"extra vertex line", "2 1\n2\n1\n1\n", 4, ErrCountMismatch},
+		{"wrong edge count", "2 2\n2\n1\n", 3, ErrCountMismatch},
+		{"neighbor out of range", "2 1\n3\n1\n", 2, common.ErrVertexOutOfRange},
+		{"self-loop", "2 1\n1 2\n1\n", 2, ErrSelfLoop},
+		{"odd weight pairs", "2 1 1\n2\n1 5\n", 2, ErrSyntax},
+		{"negative weight", "2 1 1\n2 -3\n1 -3\n", 2, common.ErrNegativeWeight},
+		{"infinite weight", "2 1 1\n2 9223372036854775807\n1 1\n", 2, common.ErrInvalidWeight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ReadGraph[int64](strings.NewReader(tt.input))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			var pe *ParseError
+			if !errors.As(err, &pe) || pe.Line != tt.line {
+				t.Errorf("expected a ParseError on line %d, got %v", tt.line, err)
+			}
+		})
+	}
+
+	// An edge listed with different weights by its endpoints is not undirected.
+	if _, _, err := ReadGraph[int64](strings.NewReader("2 1 1\n2 3\n1 4\n")); !errors.Is(err, ErrAsymmetric) {
+		t.Errorf("expected ErrAsymmetric, got %v", err)
+	}
+}
+
+func TestWriteGraph_RoundTrip(t *testing.T) {
+	g, _, err := ReadGraph[int64](strings.NewReader(weightedGraph))
+	if err != nil {
+		t.Fatalf("ReadGraph() returned an error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteGraph(&buf, g); err != nil {
+		t.Fatalf("WriteGraph() returned an error: %v", err)
+	}
+	got, f, err := ReadGraph[int64](&buf)
+	if err != nil {
+		t.Fatalf("ReadGraph() returned an error: %v", err)
+	}
+	if !f.EdgeWeights || got.N != g.N || !slices.Equal(got.Edges, g.Edges) {
+		t.Errorf("graph differs after a round trip")
+	}
+
+	unweighted, err := common.NewGraphBuilder[float64](false).WithVertices(4).AddEdge(0, 1, 1).AddEdge(1, 2, 1).Build()
+	if err != nil {
+		t.Fatalf("Build() returned an error: %v", err)
+	}
+	buf.Reset()
+	if err := WriteGraph(&buf, unweighted); err != nil {
+		t.Fatalf("WriteGraph() returned an error: %v", err)
+	}
+	if want := "4 2\n2\n1 3\n2\n\n"; buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+
+	directed, err := common.NewGraphBuilder[float64](true).AddEdge(0, 1, 1).Build()
+	if err != nil {
+		t.Fatalf("Build() returned an error: %v", err)
+	}
+	if err := WriteGraph(&buf, directed); !errors.Is(err, common.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for a directed graph, got %v", err)
+	}
+
+}
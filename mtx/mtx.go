@@ -0,0 +1,277 @@
+// Package mtx reads and writes Matrix Market coordinate files, the format of the
+// SuiteSparse matrix collection, as graphs: the entry (i, j) of a square matrix becomes
+// an edge from vertex i-1 to vertex j-1 weighted by the entry's value. Symmetric
+// matrices store only their lower triangle and become undirected graphs; general
+// matrices become directed ones. Pattern matrices, which hold no values, give every
+// edge weight 1.
+package mtx
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"playground/common"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrSyntax is reported for lines that do not follow the format.
+	ErrSyntax = errors.New("syntax error")
+	// ErrUnsupported is reported for valid Matrix Market files that do not describe a
+	// graph: dense arrays, complex values, non-square matrices and skew-symmetric or
+	// Hermitian symmetry.
+	ErrUnsupported = errors.New("unsupported matrix")
+	// ErrCountMismatch is reported when the number of entries differs from the size line.
+	ErrCountMismatch = errors.New("entry count does not match size line")
+)
+
+// ParseError reports a problem on a line of an input file.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// Field is the value type of a matrix.
+type Field string
+
+const (
+	Real    Field = "real"
+	Integer Field = "integer"
+	Pattern Field = "pattern" // No values; every entry is 1.
+)
+
+// Symmetry describes which entries a matrix file stores.
+type Symmetry string
+
+const (
+	General   Symmetry = "general"
+	Symmetric Symmetry = "symmetric" // Only the lower triangle is stored.
+)
+
+// Header is the banner line of a matrix file.
+type Header struct {
+	Field    Field
+	Symmetry Symmetry
+}
+
+// WeightMode selects how entry values become edge weights.
+type WeightMode int
+
+const (
+	// Values uses the entry values; negative values are rejected.
+	Values WeightMode = iota
+	// AbsValues uses the absolute entry values, which suits the signed matrices of
+	// most numerical collections.
+	AbsValues
+	// UnitWeights ignores the values and gives every edge weight 1.
+	UnitWeights
+)
+
+// ReadGraph reads a coordinate matrix file into a graph with one vertex per row.
+// Entries on the diagonal become self-loops. The banner of the file is returned
+// alongside.
+func ReadGraph[W common.Weight](r io.Reader, mode WeightMode) (*common.Graph[W], Header, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	errorf := func(err error, format string, args ...any) error {
+		return fmt.Errorf("mtx: %w", &ParseError{Line: line, Err: fmt.Errorf("%w: "+format, append([]any{err}, args...)...)})
+	}
+	// next returns the fields of the next line that is neither blank nor a comment.
+	next := func() ([]string, bool) {
+		for s.Scan() {
+			line++
+			if text := s.Text(); !strings.HasPrefix(text, "%") {
+				if fields := strings.Fields(text); len(fields) > 0 {
+					return fields, true
+				}
+			}
+		}
+		return nil, false
+	}
+
+	if !s.Scan() {
+		if err := s.Err(); err != nil {
+			return nil, Header{}, fmt.Errorf("mtx: %w", err)
+		}
+		return nil, Header{}, errorf(ErrSyntax, "empty file")
+	}
+	line++
+	h, err := parseBanner(s.Text())
+	if err != nil {
+		return nil, Header{}, fmt.Errorf("mtx: %w", &ParseError{Line: line, Err: err})
+	}
+
+	size, ok := next()
+	if !ok {
+		return nil, Header{}, errorf(ErrSyntax, "missing size line")
+	}
+	if len(size) != 3 {
+		return nil, Header{}, errorf(ErrSyntax, "expected \"rows columns entries\"")
+	}
+	var dims [3]int
+	for i, f := range size {
+		if dims[i], err = strconv.Atoi(f); err != nil || dims[i] < 0 {
+			return nil, Header{}, errorf(ErrSyntax, "invalid size %q", f)
+		}
+	}
+	n, nnz := dims[0], dims[2]
+	if dims[0] != dims[1] {
+		return nil, Header{}, errorf(ErrUnsupported, "%d x %d matrix is not square", dims[0], dims[1])
+	}
+	if n > math.MaxInt32 {
+		return nil, Header{}, fmt.Errorf("mtx: %w", &ParseError{Line: line, Err: &common.ParameterError{Name: "N", Value: n, Reason: fmt.Sprintf("at most %d vertices are supported", math.MaxInt32)}})
+	}
+
+	b := common.NewGraphBuilder[W](h.Symmetry == General).WithVertices(n)
+	want := 3
+	if h.Field == Pattern {
+		want = 2
+	}
+	entries := 0
+	for {
+		f, ok := next()
+		if !ok {
+			break
+		}
+		if len(f) != want {
+			return nil, Header{}, errorf(ErrSyntax, "expected %d fields per entry", want)
+		}
+		var ends [2]int
+		for i := range ends {
+			v, err := strconv.Atoi(f[i])
+			if err != nil {
+				return nil, Header{}, errorf(ErrSyntax, "invalid index %q", f[i])
+			}
+			if v < 1 || v > n {
+				return nil, Header{}, fmt.Errorf("mtx: %w", &ParseError{Line: line, Err: &common.VertexError{Vertex: v, Err: common.ErrVertexOutOfRange}})
+			}
+			ends[i] = v - 1
+		}
+		if h.Symmetry == Symmetric && ends[0] < ends[1] {
+			return nil, Header{}, errorf(ErrSyntax, "entry (%d, %d) above the diagonal of a symmetric matrix", ends[0]+1, ends[1]+1)
+		}
+		w := W(1)
+		if h.Field != Pattern && mode != UnitWeights {
+			if w, err = parseValue[W](f[2], mode); err != nil {
+				return nil, Header{}, errorf(ErrSyntax, "invalid value %q", f[2])
+			}
+			if err := common.CheckWeight(w); err != nil {
+				return nil, Header{}, fmt.Errorf("mtx: %w", &ParseError{Line: line, Err: &common.EdgeError[W]{Edge: common.Edge[W]{U: ends[0], V: ends[1], Weight: w}, Err: err}})
+			}
+		}
+		b.AddEdge(ends[0], ends[1], w)
+		entries++
+	}
+	if err := s.Err(); err != nil {
+		return nil, Header{}, fmt.Errorf("mtx: %w", err)
+	}
+	if entries != nnz {
+		return nil, Header{}, errorf(ErrCountMismatch, "read %d entries, size line announced %d", entries, nnz)
+	}
+	g, err := b.Build()
+	if err != nil {
+		return nil, Header{}, fmt.Errorf("mtx: %w", err)
+	}
+	return g, h, nil
+}
+
+// parseBanner parses "%%MatrixMarket matrix coordinate <field> <symmetry>".
+func parseBanner(text string) (Header, error) {
+	f := strings.Fields(strings.ToLower(text))
+	if len(f) != 5 || f[0] != "%%matrixmarket" || f[1] != "matrix" {
+		return Header{}, fmt.Errorf("%w: expected \"%%%%MatrixMarket matrix coordinate <field> <symmetry>\"", ErrSyntax)
+	}
+	if f[2] != "coordinate" {
+		return Header{}, fmt.Errorf("%w: %s format", ErrUnsupported, f[2])
+	}
+	h := Header{Field: Field(f[3]), Symmetry: Symmetry(f[4])}
+	switch h.Field {
+	case Real, Integer, Pattern:
+	case "complex":
+		return Header{}, fmt.Errorf("%w: complex values", ErrUnsupported)
+	default:
+		return Header{}, fmt.Errorf("%w: unknown field %q", ErrSyntax, f[3])
+	}
+	switch h.Symmetry {
+	case General, Symmetric:
+	case "skew-symmetric", "hermitian":
+		return Header{}, fmt.Errorf("%w: %s symmetry", ErrUnsupported, f[4])
+	default:
+		return Header{}, fmt.Errorf("%w: unknown symmetry %q", ErrSyntax, f[4])
+	}
+	return h, nil
+}
+
+// parseValue parses an entry value into W. Values are read as float64 first, since
+// real files may write integral values with exponents.
+func parseValue[W common.Weight](s string, mode WeightMode) (W, error) {
+	if w, err := common.ParseWeight[W](s); err == nil && (mode != AbsValues || w >= 0) {
+		return w, nil
+	}
+	x, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if mode == AbsValues && x < 0 {
+		x = -x
+	}
+	w := W(x)
+	if float64(w) != x {
+		return 0, fmt.Errorf("value %v does not fit the weight type", x)
+	}
+	return w, nil
+}
+
+// WriteGraph writes g as a coordinate matrix file. An undirected graph is written as
+// a symmetric matrix holding the edges with U >= V, a directed one as a general matrix.
+// The field is integer or real, following W.
+func WriteGraph[W common.Weight](w io.Writer, g *common.Graph[W]) error {
+	h := Header{Field: Integer, Symmetry: General}
+	if common.IsFloat[W]() {
+		h.Field = Real
+	}
+	if !g.Directed {
+		h.Symmetry = Symmetric
+	}
+	keep := func(e common.Edge[W]) bool { return g.Directed || e.U >= e.V }
+	nnz := 0
+	for u := 0; u < g.N; u++ {
+		for _, e := range g.Adj[u] {
+			if keep(e) {
+				nnz++
+			}
+		}
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%%%%MatrixMarket matrix coordinate %s %s\n", h.Field, h.Symmetry)
+	fmt.Fprintf(bw, "%d %d %d\n", g.N, g.N, nnz)
+	var buf []byte
+	for u := 0; u < g.N; u++ {
+		for _, e := range g.Adj[u] {
+			if !keep(e) {
+				continue
+			}
+			buf = strconv.AppendInt(buf[:0], int64(e.U+1), 10)
+			buf = append(buf, ' ')
+			buf = strconv.AppendInt(buf, int64(e.V+1), 10)
+			buf = append(buf, ' ')
+			buf = common.AppendWeight(buf, e.Weight)
+			buf = append(buf, '\n')
+			bw.Write(buf)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("mtx: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package mtx
+
+import (
+	"bytes"
+	"errors"
+	"playground/bmssp"
+	"playground/common"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const symmetricMatrix = `%%MatrixMarket matrix coordinate real symmetric
+% A 4 x 4 matrix in the lower-triangle storage of SuiteSparse.
+4 4 5
+1 1 4.0
+2 1 -1.5
+3 2 2.5e0
+4 3 -1
+4 1 7
+`
+
+func TestReadGraph_Symmetric(t *testing.T) {
+	g, h, err := ReadGraph[float64](strings.NewReader(symmetricMatrix), AbsValues)
+	if err != nil {
+		t.Fatalf("ReadGraph() returned an error: %v", err)
+	}
+	if h != (Header{Field: Real, Symmetry: Symmetric}) {
+		t.Errorf("unexpected header %+v", h)
+	}
+	if g.N != 4 || g.Directed || len(g.Edges) != 9 {
+		t.Fatalf("unexpected graph: N=%d, Directed=%v, %d edges", g.N, g.Directed, len(g.Edges))
+	}
+	res, err := bmssp.SolveSSSP(g, []int{0})
+	if err != nil {
+		t.Fatalf("SolveSSSP() returned an error: %v", err)
+	}
+	if want := []float64{0, 1.5, 4, 5}; !slices.Equal(res.Distances(), want) {
+		t.Errorf("expected distances %v, got %v", want, res.Distances())
+	}
+
+	if _, _, err := ReadGraph[float64](strings.NewReader(symmetricMatrix), Values); !errors.Is(err, common.ErrNegativeWeight) {
+		t.Errorf("expected ErrNegativeWeight for negative values, got %v", err)
+	}
+	g, _, err = ReadGraph[float64](strings.NewReader(symmetricMatrix), UnitWeights)
+	if err != nil {
+		t.Fatalf("ReadGraph() returned an error: %v", err)
+	}
+	for _, e := range g.Edges {
+		if e.Weight != 1 {
+			t.Fatalf("expected unit weights, got %v", e)
+		}
+	}
+}
+
+func TestReadGraph_GeneralAndPattern(t *testing.T) {
+	general := "%%MatrixMarket matrix coordinate integer general\n3 3 3\n1 2 5\n2 3 1\n3 1 2\n"
+	g, _, err := ReadGraph[int64](strings.NewReader(general), Values)
+	if err != nil {
+		t.Fatalf("ReadGraph() returned an error: %v", err)
+	}
+	if !g.Directed || len(g.Edges) != 3 || g.Edges[0] != (common.Edge[int64]{U: 0, V: 1, Weight: 5}) {
+		t.Errorf("unexpected graph %v", g.Edges)
+	}
+
+	pattern := "%%MatrixMarket matrix coordinate pattern symmetric\n3 3 2\n2 1\n3 3\n"
+	p, h, err := ReadGraph[uint32](strings.NewReader(pattern), Values)
+	if err != nil {
+		t.Fatalf("ReadGraph() returned an error: %v", err)
+	}
+	if h.Field != Pattern || p.Directed || len(p.Edges) != 3 {
+		t.Errorf("expected an undirected edge and a self-loop, got %v", p.Edges)
+	}
+}
+
+func TestReadGraph_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  int
+		want  error
+	}{
+		{"no banner", "3 3 1\n1 2 1\n", 1, ErrSyntax},
+		{"array format", "%%MatrixMarket matrix array real general\n", 1, ErrUnsupported},
+		{"complex", "%%MatrixMarket matrix coordinate complex general\n", 1, ErrUnsupported},
+		{"skew-symmetric", "%%MatrixMarket matrix coordinate real skew-symmetric\n", 1, ErrUnsupported},
+		{"not square", "%%MatrixMarket matrix coordinate real general\n2 3 0\n", 2, ErrUnsupported},
+		{"too many vertices", "%%MatrixMarket matrix coordinate real general\n9223372036854775807 9223372036854775807 0\n", 2, common.ErrInvalidParameter},
+		{"upper triangle", "%%MatrixMarket matrix coordinate real symmetric\n2 2 1\n1 2 1\n", 3, ErrSyntax},
+		{"index out of range", "%%MatrixMarket matrix coordinate real general\n2 2 1\n%\n1 3 1\n", 4, common.ErrVertexOutOfRange},
+		{"missing value", "%%MatrixMarket matrix coordinate real general\n2 2 1\n1 2\n", 3, ErrSyntax},
+		{"too few entries", "%%MatrixMarket matrix coordinate real general\n2 2 2\n1 2 1\n", 3, ErrCountMismatch},
+		{"fraction for integer weights", "%%MatrixMarket matrix coordinate real general\n2 2 1\n1 2 0.5\n", 3, ErrSyntax},
+		{"negative value", "%%MatrixMarket matrix coordinate integer general\n2 2 1\n1 2 -3\n", 3, common.ErrNegativeWeight},
+		{"infinite value", "%%MatrixMarket matrix coordinate integer general\n2 2 1\n1 2 9223372036854775807\n", 3, common.ErrInvalidWeight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ReadGraph[int64](strings.NewReader(tt.input), Values)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			var pe *ParseError
+			if !errors.As(err, &pe) || pe.Line != tt.line {
+				t.Errorf("expected a ParseError on line %d, got %v", tt.line, err)
+			}
+		})
+	}
+}
+
+func TestWriteGraph_RoundTrip(t *testing.T) {
+	for _, input := range []string{symmetricMatrix, "%%MatrixMarket matrix coordinate integer general\n3 3 3\n1 2 5\n2 3 1\n3 1 2\n"} {
+		g, _, err := ReadGraph[float64](strings.NewReader(input), AbsValues)
+		if err != nil {
+			t.Fatalf("ReadGraph() returned an error: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := WriteGraph(&buf, g); err != nil {
+			t.Fatalf("WriteGraph() returned an error: %v", err)
+		}
+		got, h, err := ReadGraph[float64](&buf, Values)
+		if err != nil {
+			t.Fatalf("ReadGraph() returned an error: %v", err)
+		}
+		if h.Field != Real || h.Symmetry == Symmetric == g.Directed || !slices.Equal(got.Edges, g.Edges) {
+			t.Errorf("graph differs after a round trip: %v vs %v", got.Edges, g.Edges)
+		}
+	}
+
+}